@@ -0,0 +1,100 @@
+package golox
+
+import (
+	"fmt"
+	"strconv"
+)
+
+func defineAssertNatives(globals *Environment) {
+	globals.define("assert", NewNativeFunctionWithOptional("assert", 1, 1, nativeAssert))
+	globals.define("assertEqual", NewNativeFunctionWithOptional("assertEqual", 2, 1, nativeAssertEqual))
+}
+
+// assert(condition, message?) - fail with a runtime error unless the
+// condition is truthy
+func nativeAssert(i *Interpreter, arguments []any) (any, error) {
+	if i.isTruthy(arguments[0]) {
+		return nil, nil
+	}
+	if arguments[1] != nil {
+		return nil, fmt.Errorf("Assertion failed: %s", i.stringify(arguments[1]))
+	}
+	return nil, fmt.Errorf("Assertion failed.")
+}
+
+// assertEqual(actual, expected, message?) - lists and maps are compared
+// by their contents rather than by identity
+func nativeAssertEqual(i *Interpreter, arguments []any) (any, error) {
+	actual, expected := arguments[0], arguments[1]
+	if i.deepEqual(actual, expected) {
+		return nil, nil
+	}
+	message := fmt.Sprintf("Expected %s but got %s.", i.describe(expected), i.describe(actual))
+	if arguments[2] != nil {
+		message = fmt.Sprintf("%s: %s", i.stringify(arguments[2]), message)
+	}
+	return nil, fmt.Errorf("%s", message)
+}
+
+// containerPair - two lists or maps being compared
+type containerPair struct {
+	a, b any
+}
+
+func (i *Interpreter) deepEqual(a any, b any) bool {
+	return i.deepEqualVisiting(a, b, make(map[containerPair]bool))
+}
+
+// deepEqualVisiting - a pair already being compared further up is taken
+// as equal, so self-containing lists and maps compare without recursing
+// forever
+func (i *Interpreter) deepEqualVisiting(a any, b any, visiting map[containerPair]bool) bool {
+	switch x := a.(type) {
+	case *LoxList:
+		y, ok := b.(*LoxList)
+		if !ok || len(x.elements) != len(y.elements) {
+			return false
+		}
+		pair := containerPair{x, y}
+		if visiting[pair] {
+			return true
+		}
+		visiting[pair] = true
+		defer delete(visiting, pair)
+
+		for n := range x.elements {
+			if !i.deepEqualVisiting(x.elements[n], y.elements[n], visiting) {
+				return false
+			}
+		}
+		return true
+	case *LoxMap:
+		y, ok := b.(*LoxMap)
+		if !ok || len(x.keys) != len(y.keys) {
+			return false
+		}
+		pair := containerPair{x, y}
+		if visiting[pair] {
+			return true
+		}
+		visiting[pair] = true
+		defer delete(visiting, pair)
+
+		for _, key := range x.keys {
+			value, ok := y.values[key]
+			if !ok || !i.deepEqualVisiting(x.values[key], value, visiting) {
+				return false
+			}
+		}
+		return true
+	}
+	return i.isEqual(a, b)
+}
+
+// describe - stringify with strings quoted, so "1" and 1 differ in messages
+func (i *Interpreter) describe(value any) string {
+	if s, ok := value.(string); ok {
+		return strconv.Quote(s)
+	}
+	return i.stringify(value)
+}
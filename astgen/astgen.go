@@ -12,9 +12,14 @@ func Generate(outputDir string) {
 		"Assign : name *Token, value Expr",
 		"Binary : left Expr, operator *Token, right Expr",
 		"Call : callee Expr, paren *Token, arguments []Expr",
+		"CompoundAssign : target Expr, operator *Token, value Expr",
+		"Conditional : condition Expr, thenBranch Expr, elseBranch Expr",
+		"Get : object Expr, name *Token",
 		"Grouping : expression Expr",
+		"Increment : target Expr, operator *Token, prefix bool",
 		"Literal : value any",
 		"Logical : left Expr, operator *Token, right Expr",
+		"Set : object Expr, name *Token, value Expr",
 		"Unary : operator *Token, right Expr",
 		"Variable : name *Token",
 	})
@@ -22,11 +27,11 @@ func Generate(outputDir string) {
 	defineAst(outputDir, "Stmt", []string{
 		"Block : statements []Stmt",
 		"Expression : expression Expr",
-		"Function : name *Token, params []*Token, body []Stmt",
+		"Function : name *Token, params []*Token, paramTypes []*Token, returnType *Token, body []Stmt",
 		"If : condition Expr, thenBranch Stmt, elseBranch Stmt",
 		"Print : expression Expr",
 		"Return : keyword *Token, value Expr",
-		"Var : name *Token, initializer Expr",
+		"Var : name *Token, typeName *Token, initializer Expr",
 		"While : condition Expr, body Stmt",
 	})
 
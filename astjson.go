@@ -0,0 +1,645 @@
+package golox
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The JSON form of a Program, version 2:
+//
+//	{"version": 2, "statements": [node...]}
+//
+// Every Stmt and Expr is an object with its node name under "type" and
+// its fields under their names in the Go structs, such as "thenBranch";
+// statements also have their "line". Missing children are null. Tokens
+// are {"lexeme": "+", "line": 3, "column": 7}, their kind follows from
+// the lexeme. Literal values are JSON values, floats always have a
+// fraction or an exponent so 1.0 stays a float. Strings are written
+// without HTML escapes, so < is not \u003c.
+//
+// Version 1 had no token columns. It is still loaded, the tokens then
+// have column 0 like tokens that did not come from the scanner.
+
+const (
+	astVersion       = 2
+	astOldestVersion = 1
+)
+
+// MarshalJSON - the AST of the program in the schema above
+func (p *Program) MarshalJSON() ([]byte, error) {
+	statements, err := (&astEncoder{lines: p.lines}).stmts(p.statements)
+	if err != nil {
+		return nil, err
+	}
+	// operators like < stay readable
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	err = encoder.Encode(map[string]any{
+		"version":    astVersion,
+		"statements": statements,
+	})
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), err
+}
+
+// UnmarshalJSON - rebuild a program from its JSON form, the result runs
+// with RunProgram like a compiled one but has no source for coverage
+func (p *Program) UnmarshalJSON(data []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var document struct {
+		Version    int               `json:"version"`
+		Statements []json.RawMessage `json:"statements"`
+	}
+	if err := decoder.Decode(&document); err != nil {
+		return err
+	}
+	if document.Version < astOldestVersion || document.Version > astVersion {
+		return fmt.Errorf("golox: unsupported AST version %d", document.Version)
+	}
+
+	loader := &astLoader{
+		lox:   NewLox(WithErrorOutput(io.Discard)),
+		lines: make(map[Stmt]int),
+	}
+	statements := make([]Stmt, len(document.Statements))
+	for n, raw := range document.Statements {
+		var node any
+		if err := decodeNumbers(raw, &node); err != nil {
+			return err
+		}
+		stmt, err := loader.stmt(fmt.Sprintf("statements[%d]", n), node)
+		if err != nil {
+			return err
+		}
+		statements[n] = stmt
+	}
+
+	p.statements = statements
+	p.lines = loader.lines
+	p.source = ""
+	p.name = ""
+	return nil
+}
+
+func decodeNumbers(data []byte, v any) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	return decoder.Decode(v)
+}
+
+type astEncoder struct {
+	lines map[Stmt]int
+}
+
+func (e *astEncoder) token(t *Token) any {
+	if t == nil {
+		return nil
+	}
+	return map[string]any{"lexeme": t.lexeme, "line": t.line, "column": t.column}
+}
+
+func (e *astEncoder) tokens(tokens []*Token) []any {
+	result := make([]any, len(tokens))
+	for n, t := range tokens {
+		result[n] = e.token(t)
+	}
+	return result
+}
+
+func (e *astEncoder) stmts(statements []Stmt) ([]any, error) {
+	result := make([]any, len(statements))
+	for n, stmt := range statements {
+		node, err := e.stmt(stmt)
+		if err != nil {
+			return nil, err
+		}
+		result[n] = node
+	}
+	return result, nil
+}
+
+func (e *astEncoder) stmt(stmt Stmt) (any, error) {
+	if stmt == nil {
+		return nil, nil
+	}
+	node := map[string]any{}
+	if line, ok := e.lines[stmt]; ok {
+		node["line"] = line
+	}
+
+	var err error
+	switch s := stmt.(type) {
+	case *Block:
+		node["type"] = "Block"
+		node["statements"], err = e.stmts(s.statements)
+	case *Expression:
+		node["type"] = "Expression"
+		node["expression"], err = e.expr(s.expression)
+	case *Function:
+		node["type"] = "Function"
+		node["name"] = e.token(s.name)
+		node["params"] = e.tokens(s.params)
+		node["paramTypes"] = e.tokens(s.paramTypes)
+		node["returnType"] = e.token(s.returnType)
+		node["body"], err = e.stmts(s.body)
+	case *If:
+		node["type"] = "If"
+		if node["condition"], err = e.expr(s.condition); err != nil {
+			return nil, err
+		}
+		if node["thenBranch"], err = e.stmt(s.thenBranch); err != nil {
+			return nil, err
+		}
+		node["elseBranch"], err = e.stmt(s.elseBranch)
+	case *Print:
+		node["type"] = "Print"
+		node["expression"], err = e.expr(s.expression)
+	case *Return:
+		node["type"] = "Return"
+		node["keyword"] = e.token(s.keyword)
+		node["value"], err = e.expr(s.value)
+	case *Var:
+		node["type"] = "Var"
+		node["name"] = e.token(s.name)
+		node["typeName"] = e.token(s.typeName)
+		node["initializer"], err = e.expr(s.initializer)
+	case *While:
+		node["type"] = "While"
+		if node["condition"], err = e.expr(s.condition); err != nil {
+			return nil, err
+		}
+		node["body"], err = e.stmt(s.body)
+	default:
+		return nil, fmt.Errorf("golox: cannot encode statement %T", stmt)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func (e *astEncoder) exprs(expressions []Expr) ([]any, error) {
+	result := make([]any, len(expressions))
+	for n, expr := range expressions {
+		node, err := e.expr(expr)
+		if err != nil {
+			return nil, err
+		}
+		result[n] = node
+	}
+	return result, nil
+}
+
+func (e *astEncoder) expr(expr Expr) (any, error) {
+	if expr == nil {
+		return nil, nil
+	}
+
+	// children are encoded in order, the first error wins
+	var err error
+	child := func(expr Expr) any {
+		if err != nil {
+			return nil
+		}
+		var node any
+		node, err = e.expr(expr)
+		return node
+	}
+
+	var node map[string]any
+	switch x := expr.(type) {
+	case *Assign:
+		node = map[string]any{"type": "Assign", "name": e.token(x.name), "value": child(x.value)}
+	case *Binary:
+		node = map[string]any{"type": "Binary", "left": child(x.left), "operator": e.token(x.operator), "right": child(x.right)}
+	case *Call:
+		var arguments []any
+		if arguments, err = e.exprs(x.arguments); err != nil {
+			return nil, err
+		}
+		node = map[string]any{"type": "Call", "callee": child(x.callee), "paren": e.token(x.paren), "arguments": arguments}
+	case *CompoundAssign:
+		node = map[string]any{"type": "CompoundAssign", "target": child(x.target), "operator": e.token(x.operator), "value": child(x.value)}
+	case *Conditional:
+		node = map[string]any{"type": "Conditional", "condition": child(x.condition), "thenBranch": child(x.thenBranch), "elseBranch": child(x.elseBranch)}
+	case *Get:
+		node = map[string]any{"type": "Get", "object": child(x.object), "name": e.token(x.name)}
+	case *Grouping:
+		node = map[string]any{"type": "Grouping", "expression": child(x.expression)}
+	case *Increment:
+		node = map[string]any{"type": "Increment", "target": child(x.target), "operator": e.token(x.operator), "prefix": x.prefix}
+	case *Literal:
+		value, valueErr := literalJSON(x.value)
+		if valueErr != nil {
+			return nil, valueErr
+		}
+		node = map[string]any{"type": "Literal", "value": value}
+	case *Logical:
+		node = map[string]any{"type": "Logical", "left": child(x.left), "operator": e.token(x.operator), "right": child(x.right)}
+	case *Set:
+		node = map[string]any{"type": "Set", "object": child(x.object), "name": e.token(x.name), "value": child(x.value)}
+	case *Unary:
+		node = map[string]any{"type": "Unary", "operator": e.token(x.operator), "right": child(x.right)}
+	case *Variable:
+		node = map[string]any{"type": "Variable", "name": e.token(x.name)}
+	default:
+		return nil, fmt.Errorf("golox: cannot encode expression %T", expr)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return node, nil
+}
+
+func literalJSON(value any) (any, error) {
+	switch v := value.(type) {
+	case nil, bool, string, int64:
+		return v, nil
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, fmt.Errorf("golox: cannot encode literal %s", formatFloat(v))
+		}
+		text := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(text, ".eE") {
+			text += ".0"
+		}
+		return json.Number(text), nil
+	}
+	return nil, fmt.Errorf("golox: cannot encode literal %v", value)
+}
+
+// astLoader - builds nodes from decoded JSON, path locates errors
+type astLoader struct {
+	lox   *Lox
+	lines map[Stmt]int
+	// functions - how deep the loader is in function bodies
+	functions int
+}
+
+func (l *astLoader) errorf(path string, format string, args ...any) error {
+	return fmt.Errorf("golox: AST %s: %s", path, fmt.Sprintf(format, args...))
+}
+
+func (l *astLoader) object(path string, node any) (map[string]any, error) {
+	object, ok := node.(map[string]any)
+	if !ok {
+		return nil, l.errorf(path, "expected an object")
+	}
+	return object, nil
+}
+
+func (l *astLoader) int(path string, value any) (int, error) {
+	number, ok := value.(json.Number)
+	if !ok {
+		return 0, l.errorf(path, "expected a number")
+	}
+	n, err := number.Int64()
+	if err != nil {
+		return 0, l.errorf(path, "expected an integer")
+	}
+	return int(n), nil
+}
+
+// Token kinds the nodes accept, what the parser would have put there
+var (
+	nameKinds      = []TokenType{TkIdentifier}
+	typeNameKinds  = []TokenType{TkIdentifier, TkNil}
+	binaryKinds    = []TokenType{TkBangEqual, TkEqualEqual, TkGreater, TkGreaterEqual, TkLess, TkLessEqual, TkPipe, TkCaret, TkAmpersand, TkLessLess, TkGreaterGreater, TkMinus, TkPlus, TkSlash, TkStar, TkPercent, TkTildeSlash, TkStarStar}
+	logicalKinds   = []TokenType{TkAnd, TkOr, TkQuestionQuestion}
+	unaryKinds     = []TokenType{TkBang, TkMinus, TkTilde}
+	compoundKinds  = []TokenType{TkPlusEqual, TkMinusEqual, TkStarEqual, TkSlashEqual}
+	incrementKinds = []TokenType{TkPlusPlus, TkMinusMinus}
+)
+
+// token - scan the lexeme again to get the kind and literal of the token,
+// which has to be one of kinds
+func (l *astLoader) token(path string, node any, kinds ...TokenType) (*Token, error) {
+	object, err := l.object(path, node)
+	if err != nil {
+		return nil, err
+	}
+	lexeme, ok := object["lexeme"].(string)
+	if !ok {
+		return nil, l.errorf(path+".lexeme", "expected a string")
+	}
+	line, err := l.int(path+".line", object["line"])
+	if err != nil {
+		return nil, err
+	}
+
+	l.lox.hadError = false
+	tokens := NewScanner(l.lox, lexeme).scanTokens()
+	if l.lox.hadError || len(tokens) != 2 || tokens[0].lexeme != lexeme {
+		return nil, l.errorf(path, "%q is not a single token", lexeme)
+	}
+	token := tokens[0]
+	if !isKind(token.kind, kinds) {
+		return nil, l.errorf(path, "%q is not allowed here", lexeme)
+	}
+	token.line = line
+	token.column = 0
+	if column, ok := object["column"]; ok {
+		if token.column, err = l.int(path+".column", column); err != nil {
+			return nil, err
+		}
+	}
+	return &token, nil
+}
+
+func isKind(kind TokenType, kinds []TokenType) bool {
+	for _, k := range kinds {
+		if kind == k {
+			return true
+		}
+	}
+	return false
+}
+
+// optionalToken - null is allowed, for type annotations
+func (l *astLoader) optionalToken(path string, node any, kinds ...TokenType) (*Token, error) {
+	if node == nil {
+		return nil, nil
+	}
+	return l.token(path, node, kinds...)
+}
+
+// tokens - entries may be null when optional is set
+func (l *astLoader) tokens(path string, node any, optional bool, kinds ...TokenType) ([]*Token, error) {
+	list, ok := node.([]any)
+	if !ok {
+		return nil, l.errorf(path, "expected an array")
+	}
+	tokens := make([]*Token, len(list))
+	for n, item := range list {
+		itemPath := fmt.Sprintf("%s[%d]", path, n)
+		var err error
+		if optional {
+			tokens[n], err = l.optionalToken(itemPath, item, kinds...)
+		} else {
+			tokens[n], err = l.token(itemPath, item, kinds...)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return tokens, nil
+}
+
+func (l *astLoader) stmts(path string, node any) ([]Stmt, error) {
+	list, ok := node.([]any)
+	if !ok {
+		return nil, l.errorf(path, "expected an array")
+	}
+	statements := make([]Stmt, len(list))
+	for n, item := range list {
+		stmt, err := l.stmt(fmt.Sprintf("%s[%d]", path, n), item)
+		if err != nil {
+			return nil, err
+		}
+		statements[n] = stmt
+	}
+	return statements, nil
+}
+
+func (l *astLoader) optionalStmt(path string, node any) (Stmt, error) {
+	if node == nil {
+		return nil, nil
+	}
+	return l.stmt(path, node)
+}
+
+func (l *astLoader) stmt(path string, node any) (Stmt, error) {
+	object, err := l.object(path, node)
+	if err != nil {
+		return nil, err
+	}
+
+	// fields are loaded in order, the first error wins; only the fields
+	// the parser may leave out can be null
+	field := func(name string) string { return path + "." + name }
+	expr := func(name string, optional bool) Expr {
+		if err != nil {
+			return nil
+		}
+		var e Expr
+		if optional {
+			e, err = l.optionalExpr(field(name), object[name])
+		} else {
+			e, err = l.expr(field(name), object[name])
+		}
+		return e
+	}
+	stmt := func(name string, optional bool) Stmt {
+		if err != nil {
+			return nil
+		}
+		var s Stmt
+		if optional {
+			s, err = l.optionalStmt(field(name), object[name])
+		} else {
+			s, err = l.stmt(field(name), object[name])
+		}
+		return s
+	}
+	stmts := func(name string) []Stmt {
+		if err != nil {
+			return nil
+		}
+		var s []Stmt
+		s, err = l.stmts(field(name), object[name])
+		return s
+	}
+	token := func(name string, optional bool, kinds ...TokenType) *Token {
+		if err != nil {
+			return nil
+		}
+		var t *Token
+		if optional {
+			t, err = l.optionalToken(field(name), object[name], kinds...)
+		} else {
+			t, err = l.token(field(name), object[name], kinds...)
+		}
+		return t
+	}
+	tokens := func(name string, optional bool, kinds ...TokenType) []*Token {
+		if err != nil {
+			return nil
+		}
+		var t []*Token
+		t, err = l.tokens(field(name), object[name], optional, kinds...)
+		return t
+	}
+
+	var result Stmt
+	switch object["type"] {
+	case "Block":
+		result = NewBlock(stmts("statements"))
+	case "Expression":
+		result = NewExpression(expr("expression", false))
+	case "Function":
+		name, params := token("name", false, nameKinds...), tokens("params", false, nameKinds...)
+		paramTypes, returnType := tokens("paramTypes", true, typeNameKinds...), token("returnType", true, typeNameKinds...)
+		l.functions++
+		body := stmts("body")
+		l.functions--
+		function := NewFunction(name, params, paramTypes, returnType, body)
+		if err == nil && len(function.paramTypes) != len(function.params) {
+			err = l.errorf(field("paramTypes"), "expected one entry per parameter")
+		}
+		result = function
+	case "If":
+		result = NewIf(expr("condition", false), stmt("thenBranch", false), stmt("elseBranch", true))
+	case "Print":
+		result = NewPrint(expr("expression", false))
+	case "Return":
+		if l.functions == 0 {
+			return nil, l.errorf(path, "return outside a function")
+		}
+		result = NewReturn(token("keyword", false, TkReturn), expr("value", true))
+	case "Var":
+		result = NewVar(token("name", false, nameKinds...), token("typeName", true, typeNameKinds...),
+			expr("initializer", true))
+	case "While":
+		result = NewWhile(expr("condition", false), stmt("body", false))
+	default:
+		return nil, l.errorf(path, "unknown statement type %v", object["type"])
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if line, ok := object["line"]; ok {
+		n, err := l.int(field("line"), line)
+		if err != nil {
+			return nil, err
+		}
+		l.lines[result] = n
+	}
+	return result, nil
+}
+
+func (l *astLoader) optionalExpr(path string, node any) (Expr, error) {
+	if node == nil {
+		return nil, nil
+	}
+	return l.expr(path, node)
+}
+
+func (l *astLoader) expr(path string, node any) (Expr, error) {
+	object, err := l.object(path, node)
+	if err != nil {
+		return nil, err
+	}
+
+	field := func(name string) string { return path + "." + name }
+	expr := func(name string) Expr {
+		if err != nil {
+			return nil
+		}
+		var e Expr
+		e, err = l.expr(field(name), object[name])
+		return e
+	}
+	token := func(name string, kinds ...TokenType) *Token {
+		if err != nil {
+			return nil
+		}
+		var t *Token
+		t, err = l.token(field(name), object[name], kinds...)
+		return t
+	}
+	// target - of compound assignments and increments, as Parser.isAssignable allows
+	target := func() Expr {
+		e := expr("target")
+		switch e.(type) {
+		case *Variable, *Get, nil:
+		default:
+			err = l.errorf(field("target"), "expected a Variable or Get")
+		}
+		return e
+	}
+
+	var result Expr
+	switch object["type"] {
+	case "Assign":
+		result = NewAssign(token("name", nameKinds...), expr("value"))
+	case "Binary":
+		result = NewBinary(expr("left"), token("operator", binaryKinds...), expr("right"))
+	case "Call":
+		callee := expr("callee")
+		paren := token("paren", TkRightParen)
+		var arguments []Expr
+		if err == nil {
+			list, ok := object["arguments"].([]any)
+			if !ok {
+				return nil, l.errorf(field("arguments"), "expected an array")
+			}
+			arguments = make([]Expr, len(list))
+			for n, item := range list {
+				if arguments[n], err = l.expr(fmt.Sprintf("%s[%d]", field("arguments"), n), item); err != nil {
+					return nil, err
+				}
+			}
+		}
+		result = NewCall(callee, paren, arguments)
+	case "CompoundAssign":
+		result = NewCompoundAssign(target(), token("operator", compoundKinds...), expr("value"))
+	case "Conditional":
+		result = NewConditional(expr("condition"), expr("thenBranch"), expr("elseBranch"))
+	case "Get":
+		result = NewGet(expr("object"), token("name", nameKinds...))
+	case "Grouping":
+		result = NewGrouping(expr("expression"))
+	case "Increment":
+		prefix, ok := object["prefix"].(bool)
+		if !ok {
+			return nil, l.errorf(field("prefix"), "expected a boolean")
+		}
+		result = NewIncrement(target(), token("operator", incrementKinds...), prefix)
+	case "Literal":
+		value, err := l.literal(field("value"), object["value"])
+		if err != nil {
+			return nil, err
+		}
+		result = NewLiteral(value)
+	case "Logical":
+		result = NewLogical(expr("left"), token("operator", logicalKinds...), expr("right"))
+	case "Set":
+		result = NewSet(expr("object"), token("name", nameKinds...), expr("value"))
+	case "Unary":
+		result = NewUnary(token("operator", unaryKinds...), expr("right"))
+	case "Variable":
+		result = NewVariable(token("name", nameKinds...))
+	default:
+		return nil, l.errorf(path, "unknown expression type %v", object["type"])
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (l *astLoader) literal(path string, value any) (any, error) {
+	switch v := value.(type) {
+	case nil, bool, string:
+		return v, nil
+	case json.Number:
+		if !strings.ContainsAny(v.String(), ".eE") {
+			if n, err := v.Int64(); err == nil {
+				return n, nil
+			}
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, l.errorf(path, "number %s out of range", v)
+		}
+		return f, nil
+	}
+	return nil, l.errorf(path, "expected a literal value")
+}
@@ -0,0 +1,148 @@
+package golox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestASTJSONRoundTrip - every script that compiles gives the same JSON
+// after a round trip and prints the same when run from the loaded AST
+func TestASTJSONRoundTrip(t *testing.T) {
+	var scripts []string
+	for _, pattern := range []string{"*.lox", "*.txt"} {
+		matches, err := filepath.Glob(filepath.Join("test", pattern))
+		if err != nil {
+			t.Fatal(err)
+		}
+		scripts = append(scripts, matches...)
+	}
+	for _, script := range scripts {
+		script := script
+		t.Run(filepath.Base(script), func(t *testing.T) {
+			source, err := os.ReadFile(script)
+			if err != nil {
+				t.Fatal(err)
+			}
+			program, err := Compile(string(source), WithErrorOutput(io.Discard))
+			if err != nil {
+				t.Skip("does not compile")
+			}
+
+			data, err := json.Marshal(program)
+			if err != nil {
+				t.Fatal(err)
+			}
+			loaded := &Program{}
+			if err := json.Unmarshal(data, loaded); err != nil {
+				t.Fatal(err)
+			}
+			again, err := json.Marshal(loaded)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(data, again) {
+				t.Fatalf("JSON changed after a round trip:\n%s\n%s", data, again)
+			}
+
+			if want, got := runForOutput(program), runForOutput(loaded); want != got {
+				t.Errorf("output of the loaded program\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
+
+func runForOutput(program *Program) string {
+	var out bytes.Buffer
+	lox := NewLox(WithOutput(&out), WithErrorOutput(&out), WithSeed(1))
+	lox.RunProgram(context.Background(), program)
+	return out.String()
+}
+
+func TestASTJSONSchema(t *testing.T) {
+	program, err := Compile("var x: number = 1.0;\nif (x > 2) print -x; else x += 1;\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := program.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`"version":2`,
+		`"typeName":{"column":8,"lexeme":"number","line":1}`,
+		`"value":1.0`,
+		`"type":"If"`,
+		`"lexeme":">"`,
+		`"line":2`,
+		`"operator":{"column":29,"lexeme":"+=","line":2}`,
+		`"elseBranch":{`,
+	} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("%s\ndoes not contain %s", data, want)
+		}
+	}
+}
+
+func TestASTJSONErrors(t *testing.T) {
+	for _, test := range []struct {
+		json string
+		want string
+	}{
+		{`{"version":3,"statements":[]}`, "unsupported AST version 3"},
+		{`{"version":2,"statements":[{"type":"Loop"}]}`, "statements[0]: unknown statement type Loop"},
+		{`{"version":2,"statements":[{"type":"Print","expression":{"type":"Unary","operator":{"lexeme":"-+","line":1},"right":null}}]}`,
+			`statements[0].expression.operator: "-+" is not a single token`},
+		{`{"version":2,"statements":[{"type":"Print","expression":{"type":"Variable","name":{"lexeme":"x"}}}]}`,
+			"statements[0].expression.name.line: expected a number"},
+		{`{"version":2,"statements":[{"type":"Print","expression":null}]}`,
+			"statements[0].expression: expected an object"},
+		{`{"version":2,"statements":[{"type":"If","condition":null,"thenBranch":{"type":"Block","statements":[]},"elseBranch":null}]}`,
+			"statements[0].condition: expected an object"},
+		{`{"version":2,"statements":[{"type":"Function","name":{"lexeme":"f","line":1},"params":[null],"paramTypes":[null],"returnType":null,"body":[]}]}`,
+			"statements[0].params[0]: expected an object"},
+		{`{"version":2,"statements":[{"type":"Print","expression":{"type":"Binary","left":{"type":"Literal","value":1},"operator":{"lexeme":"(","line":1},"right":{"type":"Literal","value":2}}}]}`,
+			`statements[0].expression.operator: "(" is not allowed here`},
+		{`{"version":2,"statements":[{"type":"Print","expression":{"type":"Unary","operator":{"lexeme":"+","line":1},"right":{"type":"Literal","value":1}}}]}`,
+			`statements[0].expression.operator: "+" is not allowed here`},
+		{`{"version":2,"statements":[{"type":"Print","expression":{"type":"Logical","left":{"type":"Literal","value":1},"operator":{"lexeme":"+","line":1},"right":{"type":"Literal","value":2}}}]}`,
+			`statements[0].expression.operator: "+" is not allowed here`},
+		{`{"version":2,"statements":[{"type":"Expression","expression":{"type":"CompoundAssign","target":{"type":"Variable","name":{"lexeme":"x","line":1}},"operator":{"lexeme":"(","line":1},"value":{"type":"Literal","value":1}}}]}`,
+			`statements[0].expression.operator: "(" is not allowed here`},
+		{`{"version":2,"statements":[{"type":"Expression","expression":{"type":"Increment","target":{"type":"Literal","value":1},"operator":{"lexeme":"++","line":1},"prefix":true}}]}`,
+			"statements[0].expression.target: expected a Variable or Get"},
+		{`{"version":2,"statements":[{"type":"Var","name":{"lexeme":"+","line":1},"typeName":null,"initializer":null}]}`,
+			`statements[0].name: "+" is not allowed here`},
+		{`{"version":2,"statements":[{"type":"Return","keyword":{"lexeme":"return","line":1},"value":null}]}`,
+			"statements[0]: return outside a function"},
+	} {
+		err := json.Unmarshal([]byte(test.json), &Program{})
+		if err == nil || !strings.Contains(err.Error(), test.want) {
+			t.Errorf("%s: got error %v, want %q", test.json, err, test.want)
+		}
+	}
+}
+
+func TestASTJSONVersion1(t *testing.T) {
+	data := `{"version":1,"statements":[{"type":"Print","line":1,"expression":` +
+		`{"type":"Binary","left":{"type":"Literal","value":1},"operator":{"lexeme":"<","line":1},"right":{"type":"Literal","value":2}}}]}`
+	program := &Program{}
+	if err := json.Unmarshal([]byte(data), program); err != nil {
+		t.Fatal(err)
+	}
+	if output := runForOutput(program); output != "true\n" {
+		t.Errorf("output %q, expected true", output)
+	}
+	encoded, err := program.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `"operator":{"column":0,"lexeme":"<","line":1}`; !strings.Contains(string(encoded), want) {
+		t.Errorf("%s\ndoes not contain %s", encoded, want)
+	}
+}
@@ -0,0 +1,573 @@
+package golox
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// Bridging between Go values and Lox values for embedders.
+//
+// ToLox wraps structs, maps, slices and funcs so scripts read and write the
+// Go value itself: struct fields and methods through obj.name, maps through
+// obj.key or get/set, slices through get/set. Pass a pointer to a struct to
+// make its fields writable. FromLox goes the other way and converts a Lox
+// value into a typed Go value.
+
+// propertyHolder - values that support obj.name and obj.name = value
+type propertyHolder interface {
+	getProperty(name string) (any, error)
+	setProperty(name string, value any) error
+}
+
+// ConversionError - a Lox value that doesn't fit the Go type asked for
+type ConversionError struct {
+	Path   string
+	From   string
+	To     reflect.Type
+	Reason string
+}
+
+func (e *ConversionError) Error() string {
+	message := fmt.Sprintf("cannot convert %s to %s", e.From, e.To)
+	if e.Path != "" {
+		message += " at " + e.Path
+	}
+	if e.Reason != "" {
+		message += ": " + e.Reason
+	}
+	return message
+}
+
+// ToLox - Go value as a Lox value, numbers become int64 or float64
+func ToLox(value any) any {
+	switch v := value.(type) {
+	case nil, bool, int64, float64, string,
+		*LoxList, *LoxMap, LoxTime, LoxCallable:
+		return value
+	case time.Time:
+		return LoxTime{v}
+	}
+	return toLox(reflect.ValueOf(value))
+}
+
+func toLox(v reflect.Value) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if v.CanInterface() {
+		if t, ok := v.Interface().(time.Time); ok {
+			return LoxTime{t}
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if v.Uint() > math.MaxInt64 {
+			return float64(v.Uint())
+		}
+		return int64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	case reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return ToLox(v.Elem().Interface())
+	case reflect.Ptr:
+		if v.IsNil() {
+			return nil
+		}
+		if v.Elem().Kind() == reflect.Struct {
+			// addressable, so fields can be set and pointer methods called
+			return &goObject{v.Elem()}
+		}
+		return toLox(v.Elem())
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		return &goMap{v}
+	case reflect.Slice:
+		if v.IsNil() {
+			return nil
+		}
+		return &goSlice{v}
+	case reflect.Array:
+		return &goSlice{v}
+	case reflect.Func:
+		if v.IsNil() {
+			return nil
+		}
+		return &goFunction{"func", v}
+	}
+	return &goObject{v}
+}
+
+// FromLox - store value into the Go variable target points to
+func FromLox(value any, target any) error {
+	rv := reflect.ValueOf(target)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return fmt.Errorf("golox: FromLox target must be a non-nil pointer, got %T", target)
+	}
+	converted, err := fromLox(value, rv.Elem().Type(), "")
+	if err != nil {
+		return err
+	}
+	rv.Elem().Set(converted)
+	return nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func fromLox(value any, t reflect.Type, path string) (reflect.Value, error) {
+	fail := func(reason string) (reflect.Value, error) {
+		return reflect.Value{}, &ConversionError{path, typeName(value), t, reason}
+	}
+
+	// bridged Go values convert back to themselves
+	if w, ok := value.(interface{ goValue() reflect.Value }); ok {
+		v := w.goValue()
+		if v.Type().AssignableTo(t) {
+			return v, nil
+		}
+		if v.CanAddr() && v.Addr().Type().AssignableTo(t) {
+			return v.Addr(), nil
+		}
+		return fail("")
+	}
+
+	if value == nil {
+		switch t.Kind() {
+		case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice, reflect.Func:
+			return reflect.Zero(t), nil
+		}
+		return fail("")
+	}
+
+	if t.Kind() == reflect.Interface {
+		natural := reflect.ValueOf(naturalGo(value))
+		if !natural.Type().AssignableTo(t) {
+			return fail(fmt.Sprintf("%s does not implement it", natural.Type()))
+		}
+		return natural, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		if b, ok := value.(bool); ok {
+			return reflect.ValueOf(b).Convert(t), nil
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, ok := value.(int64)
+		if f, isFloat := value.(float64); isFloat {
+			if math.Trunc(f) != f || f < math.MinInt64 || f >= math.MaxInt64 {
+				return fail("not an integer")
+			}
+			n, ok = int64(f), true
+		}
+		if ok {
+			out := reflect.New(t).Elem()
+			if out.OverflowInt(n) {
+				return fail("out of range")
+			}
+			out.SetInt(n)
+			return out, nil
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, ok := value.(int64)
+		if f, isFloat := value.(float64); isFloat {
+			if math.Trunc(f) != f || f < 0 || f >= math.MaxUint64 {
+				return fail("not an unsigned integer")
+			}
+			return reflect.ValueOf(uint64(f)).Convert(t), nil
+		}
+		if ok {
+			out := reflect.New(t).Elem()
+			if n < 0 || out.OverflowUint(uint64(n)) {
+				return fail("out of range")
+			}
+			out.SetUint(uint64(n))
+			return out, nil
+		}
+	case reflect.Float32, reflect.Float64:
+		if isNumber(value) {
+			out := reflect.New(t).Elem()
+			f := toFloat(value)
+			if out.OverflowFloat(f) {
+				return fail("out of range")
+			}
+			out.SetFloat(f)
+			return out, nil
+		}
+	case reflect.String:
+		if s, ok := value.(string); ok {
+			return reflect.ValueOf(s).Convert(t), nil
+		}
+	case reflect.Slice, reflect.Array:
+		list, ok := value.(*LoxList)
+		if !ok {
+			break
+		}
+		var out reflect.Value
+		if t.Kind() == reflect.Slice {
+			out = reflect.MakeSlice(t, len(list.elements), len(list.elements))
+		} else {
+			if t.Len() != len(list.elements) {
+				return fail(fmt.Sprintf("need %d elements, got %d", t.Len(), len(list.elements)))
+			}
+			out = reflect.New(t).Elem()
+		}
+		for n, element := range list.elements {
+			converted, err := fromLox(element, t.Elem(), fmt.Sprintf("%s[%d]", path, n))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.Index(n).Set(converted)
+		}
+		return out, nil
+	case reflect.Map:
+		m, ok := value.(*LoxMap)
+		if !ok {
+			break
+		}
+		if t.Key().Kind() != reflect.String {
+			return fail("map keys must be strings")
+		}
+		out := reflect.MakeMapWithSize(t, len(m.keys))
+		for _, key := range m.keys {
+			converted, err := fromLox(m.values[key], t.Elem(), fmt.Sprintf("%s[%q]", path, key))
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), converted)
+		}
+		return out, nil
+	case reflect.Struct:
+		if lt, ok := value.(LoxTime); ok && t == timeType {
+			return reflect.ValueOf(lt.time), nil
+		}
+		m, ok := value.(*LoxMap)
+		if !ok {
+			break
+		}
+		out := reflect.New(t).Elem()
+		for _, key := range m.keys {
+			field, ok := structField(t, key)
+			if !ok {
+				return fail(fmt.Sprintf("no exported field %q", key))
+			}
+			converted, err := fromLox(m.values[key], field.Type, path+"."+field.Name)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+			out.FieldByIndex(field.Index).Set(converted)
+		}
+		return out, nil
+	case reflect.Ptr:
+		converted, err := fromLox(value, t.Elem(), path)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		out := reflect.New(t.Elem())
+		out.Elem().Set(converted)
+		return out, nil
+	}
+	return fail("")
+}
+
+// structField - exported field by its name or its `lox:"name"` tag
+func structField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for n := 0; n < t.NumField(); n++ {
+		field := t.Field(n)
+		if field.PkgPath == "" && field.Tag.Get("lox") == name {
+			return field, true
+		}
+	}
+	field, ok := t.FieldByName(name)
+	if !ok || field.PkgPath != "" {
+		return reflect.StructField{}, false
+	}
+	return field, true
+}
+
+// naturalGo - Go value for an interface typed target
+func naturalGo(value any) any {
+	switch v := value.(type) {
+	case *LoxList:
+		out := make([]any, len(v.elements))
+		for n, element := range v.elements {
+			out[n] = naturalGo(element)
+		}
+		return out
+	case *LoxMap:
+		out := make(map[string]any, len(v.keys))
+		for _, key := range v.keys {
+			out[key] = naturalGo(v.values[key])
+		}
+		return out
+	case LoxTime:
+		return v.time
+	case interface{ goValue() reflect.Value }:
+		return v.goValue().Interface()
+	}
+	return value
+}
+
+// typeName - name of a value's Lox type for messages
+func typeName(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "nil"
+	case bool:
+		return "boolean"
+	case int64:
+		return "integer"
+	case float64:
+		return "float"
+	case string:
+		return "string"
+	case *LoxList:
+		return "list"
+	case *LoxMap:
+		return "map"
+	case LoxTime:
+		return "time"
+	case interface{ goValue() reflect.Value }:
+		return v.goValue().Type().String()
+	case LoxCallable:
+		return "function"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// goObject - Go struct, or any other Go value, seen through its
+// exported fields and methods
+type goObject struct {
+	value reflect.Value
+}
+
+func (o *goObject) goValue() reflect.Value {
+	return o.value
+}
+
+func (o *goObject) getProperty(name string) (any, error) {
+	if o.value.Kind() == reflect.Struct {
+		if field, ok := structField(o.value.Type(), name); ok {
+			return toLox(o.value.FieldByIndex(field.Index)), nil
+		}
+	}
+	method := o.value.MethodByName(name)
+	if o.value.CanAddr() {
+		method = o.value.Addr().MethodByName(name)
+	}
+	if method.IsValid() {
+		return &goFunction{name, method}, nil
+	}
+	return nil, fmt.Errorf("Undefined property '%s'.", name)
+}
+
+func (o *goObject) setProperty(name string, value any) error {
+	if o.value.Kind() != reflect.Struct {
+		return fmt.Errorf("Undefined property '%s'.", name)
+	}
+	field, ok := structField(o.value.Type(), name)
+	if !ok {
+		return fmt.Errorf("Undefined property '%s'.", name)
+	}
+	target := o.value.FieldByIndex(field.Index)
+	if !target.CanSet() {
+		return fmt.Errorf("Cannot set '%s' on a copy of %s, expose a pointer to it.", name, o.value.Type())
+	}
+	converted, err := fromLox(value, target.Type(), name)
+	if err != nil {
+		return fmt.Errorf("Cannot set '%s': %s.", name, err)
+	}
+	target.Set(converted)
+	return nil
+}
+
+func (o *goObject) String() string {
+	return fmt.Sprint(o.value.Interface())
+}
+
+// goMap - Go map, keys read with obj.key or get(obj, key)
+type goMap struct {
+	value reflect.Value
+}
+
+func (m *goMap) goValue() reflect.Value {
+	return m.value
+}
+
+func (m *goMap) key(key any) (reflect.Value, error) {
+	converted, err := fromLox(key, m.value.Type().Key(), "")
+	if err != nil {
+		return reflect.Value{}, fmt.Errorf("Invalid map key: %s.", err)
+	}
+	return converted, nil
+}
+
+func (m *goMap) length() int {
+	return m.value.Len()
+}
+
+func (m *goMap) index(key any) (any, error) {
+	k, err := m.key(key)
+	if err != nil {
+		return nil, err
+	}
+	return toLox(m.value.MapIndex(k)), nil
+}
+
+func (m *goMap) setIndex(key any, value any) error {
+	k, err := m.key(key)
+	if err != nil {
+		return err
+	}
+	converted, err := fromLox(value, m.value.Type().Elem(), fmt.Sprint(key))
+	if err != nil {
+		return fmt.Errorf("Cannot set map entry: %s.", err)
+	}
+	m.value.SetMapIndex(k, converted)
+	return nil
+}
+
+// keyList - keys sorted by their text so scripts see a stable order
+func (m *goMap) keyList() []any {
+	keys := m.value.MapKeys()
+	sort.Slice(keys, func(a, b int) bool {
+		return fmt.Sprint(keys[a]) < fmt.Sprint(keys[b])
+	})
+	out := make([]any, len(keys))
+	for n, key := range keys {
+		out[n] = toLox(key)
+	}
+	return out
+}
+
+func (m *goMap) getProperty(name string) (any, error) {
+	return m.index(name)
+}
+
+func (m *goMap) setProperty(name string, value any) error {
+	return m.setIndex(name, value)
+}
+
+func (m *goMap) String() string {
+	return fmt.Sprint(m.value.Interface())
+}
+
+// goSlice - Go slice or array, elements read with get(obj, index)
+type goSlice struct {
+	value reflect.Value
+}
+
+func (s *goSlice) goValue() reflect.Value {
+	return s.value
+}
+
+func (s *goSlice) length() int {
+	return s.value.Len()
+}
+
+func (s *goSlice) index(key any) (any, error) {
+	index, err := listIndex(s.value.Len(), key)
+	if err != nil {
+		return nil, err
+	}
+	return toLox(s.value.Index(int(index))), nil
+}
+
+func (s *goSlice) setIndex(key any, value any) error {
+	index, err := listIndex(s.value.Len(), key)
+	if err != nil {
+		return err
+	}
+	target := s.value.Index(int(index))
+	if !target.CanSet() {
+		return fmt.Errorf("Cannot set elements of a copy of %s, expose a pointer to it.", s.value.Type())
+	}
+	converted, err := fromLox(value, target.Type(), fmt.Sprintf("[%d]", index))
+	if err != nil {
+		return fmt.Errorf("Cannot set list element: %s.", err)
+	}
+	target.Set(converted)
+	return nil
+}
+
+func (s *goSlice) String() string {
+	return fmt.Sprint(s.value.Interface())
+}
+
+// goFunction - Go func or bound method, arguments are converted to the
+// parameter types and a trailing error result becomes a runtime error
+type goFunction struct {
+	name  string
+	value reflect.Value
+}
+
+func (f *goFunction) goValue() reflect.Value {
+	return f.value
+}
+
+func (f *goFunction) arity() int {
+	return f.value.Type().NumIn()
+}
+
+func (f *goFunction) call(interpreter *Interpreter, arguments []any) (result any, err error) {
+	t := f.value.Type()
+	in := make([]reflect.Value, len(arguments))
+	for n, argument := range arguments {
+		converted, err := fromLox(argument, t.In(n), fmt.Sprintf("argument %d", n+1))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s.", f.name, err)
+		}
+		in[n] = converted
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			result, err = nil, fmt.Errorf("%s panicked: %v.", f.name, r)
+		}
+	}()
+
+	var out []reflect.Value
+	if t.IsVariadic() {
+		out = f.value.CallSlice(in)
+	} else {
+		out = f.value.Call(in)
+	}
+
+	errorType := reflect.TypeOf((*error)(nil)).Elem()
+	if len(out) > 0 && t.Out(len(out)-1) == errorType {
+		if e := out[len(out)-1]; !e.IsNil() {
+			return nil, e.Interface().(error)
+		}
+		out = out[:len(out)-1]
+	}
+
+	switch len(out) {
+	case 0:
+		return nil, nil
+	case 1:
+		return toLox(out[0]), nil
+	}
+	results := make([]any, len(out))
+	for n, value := range out {
+		results[n] = toLox(value)
+	}
+	return NewLoxList(results), nil
+}
+
+func (f *goFunction) String() string {
+	return fmt.Sprintf("<go fn %s>", f.name)
+}
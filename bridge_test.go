@@ -0,0 +1,107 @@
+package golox
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+type bridgeUser struct {
+	Name   string
+	Age    int
+	Tags   []string
+	secret string
+}
+
+func (u *bridgeUser) Greet(greeting string) string {
+	return greeting + ", " + u.Name
+}
+
+func (u *bridgeUser) Check(age int) error {
+	if age < 0 {
+		return errors.New("negative age")
+	}
+	return nil
+}
+
+func TestBridge_GoValuesInScripts(t *testing.T) {
+	user := &bridgeUser{Name: "Ann", Age: 41, Tags: []string{"a", "b"}, secret: "x"}
+	scores := map[string]int{"ann": 3}
+
+	lox := NewLox()
+	lox.Define("user", user)
+	lox.Define("scores", scores)
+	err := lox.Run(`
+		user.Age += 1;
+		user.Name = user.Name + "a";
+		set(user.Tags, 1, "c");
+		scores.bob = 5;
+		var greeting = user.Greet("Hi");
+		var tagCount = len(user.Tags);
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if user.Age != 42 || user.Name != "Anna" || user.Tags[1] != "c" {
+		t.Errorf("user after script %+v, expected Age 42, Name Anna, Tags[1] c", user)
+	}
+	if scores["bob"] != 5 {
+		t.Errorf("scores after script %v, expected bob 5", scores)
+	}
+	var greeting string
+	value, _ := lox.Global("greeting")
+	if err := FromLox(value, &greeting); err != nil || greeting != "Hi, Anna" {
+		t.Errorf("greeting %q (%v), expected Hi, Anna", greeting, err)
+	}
+
+	for _, source := range []string{
+		`user.secret;`,
+		`user.Age = "old";`,
+		`user.Check(-1);`,
+	} {
+		var runtimeErr RuntimeError
+		if err := lox.Run(source); !errors.As(err, &runtimeErr) {
+			t.Errorf("Run(%q) error %v, expected a RuntimeError", source, err)
+		}
+	}
+}
+
+func TestFromLox(t *testing.T) {
+	type item struct {
+		ID    int64   `lox:"id"`
+		Price float32 `lox:"price"`
+	}
+
+	m := NewLoxMap()
+	m.set("id", int64(7))
+	m.set("price", 2.5)
+	list := NewLoxList([]any{m})
+
+	var items []item
+	if err := FromLox(list, &items); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(items) != "[{7 2.5}]" {
+		t.Errorf("FromLox result %v, expected [{7 2.5}]", items)
+	}
+
+	m.set("id", 1.5)
+	err := FromLox(list, &items)
+	var conversionErr *ConversionError
+	if !errors.As(err, &conversionErr) || conversionErr.Path != "[0].ID" {
+		t.Errorf("FromLox error %v, expected a ConversionError at [0].ID", err)
+	}
+
+	var small int8
+	if err := FromLox(int64(300), &small); err == nil {
+		t.Errorf("FromLox(300, *int8) succeeded, expected out of range")
+	}
+	var anything any
+	if err := FromLox(list, &anything); err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(anything) != "[map[id:1.5 price:2.5]]" {
+		t.Errorf("FromLox into any %v, expected [map[id:1.5 price:2.5]]", anything)
+	}
+}
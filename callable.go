@@ -7,6 +7,13 @@ type LoxCallable interface {
 	call(interpreter *Interpreter, arguments []any) (any, error)
 }
 
+// optionalArity - callables that also accept up to optional() trailing
+// arguments after the arity() required ones
+type optionalArity interface {
+	LoxCallable
+	optional() int
+}
+
 type clock struct{}
 
 func NewClock() *clock {
@@ -17,8 +24,10 @@ func (c *clock) arity() int {
 	return 0
 }
 
+// call - seconds since the Unix epoch with sub-second resolution
 func (c *clock) call(i *Interpreter, arguments []any) (any, error) {
-	return time.Now().Unix(), nil
+	now := i.timeSource.Now()
+	return float64(now.UnixNano()) / float64(time.Second), nil
 }
 
 func (c *clock) String() string {
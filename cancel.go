@@ -0,0 +1,21 @@
+package golox
+
+import "fmt"
+
+// CancelError - the context given by the host ended while the script ran,
+// errors.Is matches it against context.Canceled or DeadlineExceeded
+type CancelError struct {
+	Err error
+}
+
+func NewCancelError(err error) CancelError {
+	return CancelError{err}
+}
+
+func (e CancelError) Error() string {
+	return fmt.Sprintf("script cancelled: %s", e.Err)
+}
+
+func (e CancelError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,49 @@
+package golox
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLox_RunContextCancels(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"loop", `while (true) {}`},
+		{"calls", `fun spin(n) { return n; } var i = 0; for (;;) i = spin(i + 1);`},
+		{"sleep", `sleep(60);`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			err := NewLox().RunContext(ctx, tt.source)
+			var cancelErr CancelError
+			if !errors.As(err, &cancelErr) || !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("RunContext error %v, expected a CancelError for the deadline", err)
+			}
+		})
+	}
+}
+
+func TestCallable_CallContextCancels(t *testing.T) {
+	lox := NewLox()
+	if err := lox.Run(`fun forever() { while (true) {} }`); err != nil {
+		t.Fatal(err)
+	}
+	callable, err := lox.Callable("forever")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := callable.CallContext(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("CallContext error %v, expected context.Canceled", err)
+	}
+}
@@ -1,6 +1,11 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
 	"os"
 
 	"github.com/detohm/golox"
@@ -8,6 +13,291 @@ import (
 
 func main() {
 
-	lox := golox.NewLox()
-	lox.Main(os.Args)
+	seed := flag.Int64("seed", 0, "seed for the random natives, time based if not set")
+	cover := flag.Bool("cover", false, "print a coverage summary when the script or tests finish")
+	coverProfile := flag.String("coverprofile", "", "write lcov coverage data to `file`")
+	coverListing := flag.String("coverlisting", "", "write the source annotated with coverage to `file`")
+	profile := flag.Bool("profile", false, "print where the time went when the script or tests finish")
+	pprofFile := flag.String("pprof", "", "write a profile for go tool pprof to `file`")
+	typeCheck := flag.Bool("typecheck", false, "check type annotations before running or linting")
+	diagnosticsFormat := flag.String("diagnostics", "", "report errors and lint warnings as `format` json (one object per line) or sarif")
+	diagnosticsOut := flag.String("diagnosticsout", "", "write the -diagnostics report to `file` instead of standard output")
+	flag.Parse()
+
+	// scripts run from the command line may use files below the working
+	// directory and read the environment
+	options := []golox.Option{
+		golox.WithFileRoot("."),
+		golox.WithEnvLookup(os.LookupEnv),
+	}
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			options = append(options, golox.WithSeed(*seed))
+		}
+	})
+
+	if *typeCheck {
+		options = append(options, golox.WithTypeCheck())
+	}
+	var coverage *golox.Coverage
+	if *cover || *coverProfile != "" || *coverListing != "" {
+		coverage = golox.NewCoverage()
+		options = append(options, golox.WithCoverage(coverage))
+	}
+	var profiler *golox.Profiler
+	if *profile || *pprofFile != "" {
+		profiler = golox.NewProfiler()
+		options = append(options, golox.WithProfiler(profiler))
+	}
+	var diagnostics *golox.Diagnostics
+	switch *diagnosticsFormat {
+	case "":
+	case "json", "sarif":
+		diagnostics = golox.NewDiagnostics()
+		options = append(options, golox.WithDiagnostics(diagnostics))
+	default:
+		fmt.Printf("Unknown diagnostics format %q, expected json or sarif.\n", *diagnosticsFormat)
+		os.Exit(64)
+	}
+
+	status := 0
+	switch {
+	case flag.NArg() > 0 && flag.Arg(0) == "test":
+		status = runTests(flag.Args()[1:], options)
+	case flag.NArg() > 0 && flag.Arg(0) == "lint":
+		status = runLint(flag.Args()[1:], *typeCheck, diagnostics)
+	case flag.NArg() > 0 && flag.Arg(0) == "ast":
+		status = runAST(flag.Args()[1:])
+	case flag.NArg() > 0 && flag.Arg(0) == "tokens":
+		status = runTokens(flag.Args()[1:])
+	case flag.NArg() > 0 && flag.Arg(0) == "highlight":
+		status = runHighlight(flag.Args()[1:])
+	case (coverage != nil || profiler != nil || diagnostics != nil) && flag.NArg() > 0:
+		// Main exits on errors, the reports have to be written first
+		options = append(options, golox.WithArgs(flag.Args()[1:]))
+		var err error
+		status, err = golox.NewLox(options...).RunFile(flag.Arg(0))
+		if err != nil {
+			fmt.Println(err)
+		}
+	default:
+		lox := golox.NewLox(options...)
+		lox.Main(append([]string{os.Args[0]}, flag.Args()...))
+	}
+
+	if coverage != nil {
+		if err := writeCoverage(coverage, *cover, *coverProfile, *coverListing); err != nil {
+			fmt.Println(err)
+			status = 1
+		}
+	}
+	if profiler != nil {
+		if err := writeProfile(profiler, *profile, *pprofFile); err != nil {
+			fmt.Println(err)
+			status = 1
+		}
+	}
+	if diagnostics != nil {
+		if err := writeDiagnostics(diagnostics, *diagnosticsFormat, *diagnosticsOut); err != nil {
+			fmt.Println(err)
+			status = 1
+		}
+	}
+	os.Exit(status)
+}
+
+// runTests - golox test [paths...], the *_test.lox files below the working
+// directory by default; returns the exit status
+func runTests(paths []string, options []golox.Option) int {
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	summary, err := golox.RunTests(context.Background(), os.Stdout, paths, options...)
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	if summary.Failed > 0 {
+		return 1
+	}
+	return 0
+}
+
+// runLint - golox lint files..., prints file:line: message (rule) for
+// every warning or adds them to diagnostics, type checking with
+// -typecheck; returns 1 when there were warnings, syntax or type errors
+func runLint(paths []string, typeCheck bool, diagnostics *golox.Diagnostics) int {
+	status := 0
+	for _, path := range paths {
+		source, err := os.ReadFile(path)
+		if err != nil {
+			fmt.Println(err)
+			status = 1
+			continue
+		}
+
+		// Lint does not know the path, the errors of each file are
+		// collected on their own to fill it in
+		var options []golox.Option
+		fileDiagnostics := golox.NewDiagnostics()
+		if diagnostics != nil {
+			options = append(options, golox.WithDiagnostics(fileDiagnostics))
+		}
+		if typeCheck {
+			options = append(options, golox.WithTypeCheck())
+		}
+		warnings, err := golox.Lint(string(source), options...)
+		for _, diagnostic := range fileDiagnostics.List() {
+			diagnostic.File = path
+			diagnostics.Add(diagnostic)
+		}
+		if err != nil {
+			status = 1
+		}
+		for _, warning := range warnings {
+			if diagnostics != nil {
+				diagnostics.Add(warning.Diagnostic(path))
+			} else {
+				fmt.Printf("%s:%d: %s (%s)\n", path, warning.Line, warning.Message, warning.Rule)
+			}
+			status = 1
+		}
+	}
+	return status
+}
+
+// runAST - golox ast file, prints the syntax tree of file as JSON
+func runAST(paths []string) int {
+	if len(paths) != 1 {
+		fmt.Println("Usage: golox ast script")
+		return 64
+	}
+	source, err := os.ReadFile(paths[0])
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	program, err := golox.Compile(string(source))
+	if err != nil {
+		return 65
+	}
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(program); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	return 0
+}
+
+// runTokens - golox tokens file, prints what the scanner makes of file
+func runTokens(paths []string) int {
+	if len(paths) != 1 {
+		fmt.Println("Usage: golox tokens script")
+		return 64
+	}
+	source, err := os.ReadFile(paths[0])
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	tokens, scanErr := golox.ScanTokens(string(source))
+	if err := golox.WriteTokens(os.Stdout, tokens); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	if scanErr != nil {
+		return 65
+	}
+	return 0
+}
+
+// runHighlight - golox highlight [-html] file, prints file with terminal
+// colors or as HTML
+func runHighlight(args []string) int {
+	flags := flag.NewFlagSet("highlight", flag.ExitOnError)
+	asHTML := flags.Bool("html", false, "write HTML instead of terminal colors")
+	flags.Parse(args)
+	if flags.NArg() != 1 {
+		fmt.Println("Usage: golox highlight [-html] script")
+		return 64
+	}
+	source, err := os.ReadFile(flags.Arg(0))
+	if err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	format := golox.HighlightANSI
+	if *asHTML {
+		format = golox.HighlightHTML
+	}
+	if err := golox.Highlight(os.Stdout, string(source), format); err != nil {
+		fmt.Println(err)
+		return 1
+	}
+	return 0
+}
+
+func writeCoverage(coverage *golox.Coverage, summary bool, profile string, listing string) error {
+	if summary {
+		coverage.WriteSummary(os.Stdout)
+	}
+	if profile != "" {
+		if err := writeFile(profile, coverage.WriteLcov); err != nil {
+			return err
+		}
+	}
+	if listing != "" {
+		if err := writeFile(listing, coverage.WriteAnnotated); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func writeFile(path string, write func(w io.Writer)) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	write(file)
+	return file.Close()
+}
+
+func writeDiagnostics(diagnostics *golox.Diagnostics, format string, path string) error {
+	write := diagnostics.WriteJSON
+	if format == "sarif" {
+		write = diagnostics.WriteSARIF
+	}
+	if path == "" {
+		return write(os.Stdout)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func writeProfile(profiler *golox.Profiler, report bool, pprofFile string) error {
+	if report {
+		profiler.WriteReport(os.Stdout)
+	}
+	if pprofFile != "" {
+		file, err := os.Create(pprofFile)
+		if err != nil {
+			return err
+		}
+		if err := profiler.WriteProfile(file); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	}
+	return nil
 }
@@ -0,0 +1,95 @@
+package golox
+
+import "fmt"
+
+func defineCollectionNatives(globals *Environment) {
+	globals.define("newList", NewNativeFunction("newList", 0, nativeNewList))
+	globals.define("newMap", NewNativeFunction("newMap", 0, nativeNewMap))
+	globals.define("len", NewNativeFunction("len", 1, nativeLen))
+	globals.define("get", NewNativeFunction("get", 2, nativeGet))
+	globals.define("set", NewNativeFunction("set", 3, nativeSet))
+	globals.define("push", NewNativeFunction("push", 2, nativePush))
+	globals.define("keys", NewNativeFunction("keys", 1, nativeKeys))
+}
+
+func nativeNewList(i *Interpreter, arguments []any) (any, error) {
+	return NewLoxList([]any{}), nil
+}
+
+func nativeNewMap(i *Interpreter, arguments []any) (any, error) {
+	return NewLoxMap(), nil
+}
+
+// indexable - containers that len, get and set work on
+type indexable interface {
+	length() int
+	index(key any) (any, error)
+	setIndex(key any, value any) error
+}
+
+// keyed - containers that keys works on
+type keyed interface {
+	keyList() []any
+}
+
+// len(value) - length of a string or a container
+func nativeLen(i *Interpreter, arguments []any) (any, error) {
+	switch v := arguments[0].(type) {
+	case string:
+		return int64(len(v)), nil
+	case indexable:
+		return int64(v.length()), nil
+	}
+	return nil, fmt.Errorf("Can only take the length of strings, lists and maps.")
+}
+
+// get(list, index) or get(map, key) - nil for a missing map key
+func nativeGet(i *Interpreter, arguments []any) (any, error) {
+	container, ok := arguments[0].(indexable)
+	if !ok {
+		return nil, fmt.Errorf("Can only index into lists and maps.")
+	}
+	return container.index(arguments[1])
+}
+
+// set(list, index, value) or set(map, key, value)
+func nativeSet(i *Interpreter, arguments []any) (any, error) {
+	container, ok := arguments[0].(indexable)
+	if !ok {
+		return nil, fmt.Errorf("Can only index into lists and maps.")
+	}
+	if err := container.setIndex(arguments[1], arguments[2]); err != nil {
+		return nil, err
+	}
+	return arguments[2], nil
+}
+
+// push(list, value) - append to the end of a list
+func nativePush(i *Interpreter, arguments []any) (any, error) {
+	list, ok := arguments[0].(*LoxList)
+	if !ok {
+		return nil, fmt.Errorf("Can only push onto lists.")
+	}
+	list.elements = append(list.elements, arguments[1])
+	return nil, nil
+}
+
+// keys(map) - list of keys, in insertion order for Lox maps
+func nativeKeys(i *Interpreter, arguments []any) (any, error) {
+	m, ok := arguments[0].(keyed)
+	if !ok {
+		return nil, fmt.Errorf("Can only take the keys of maps.")
+	}
+	return NewLoxList(m.keyList()), nil
+}
+
+func listIndex(length int, argument any) (int64, error) {
+	index, ok := argument.(int64)
+	if !ok {
+		return 0, fmt.Errorf("List index must be an integer.")
+	}
+	if index < 0 || index >= int64(length) {
+		return 0, fmt.Errorf("List index %d out of range.", index)
+	}
+	return index, nil
+}
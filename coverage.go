@@ -0,0 +1,229 @@
+package golox
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Coverage - counts how often each statement ran and which way each if
+// and each and/or/?? went, across every interpreter created WithCoverage.
+// Only programs compiled with a name, such as script files and test files,
+// show up in the reports. Not safe for concurrent use.
+type Coverage struct {
+	files      []coveredFile
+	statements map[Stmt]int
+	// branches - for an If how often the then and else branch ran, for a
+	// Logical how often it short-circuited and evaluated its right side
+	branches map[any]*[2]int
+}
+
+type coveredFile struct {
+	name    string
+	program *Program
+}
+
+func NewCoverage() *Coverage {
+	return &Coverage{
+		statements: make(map[Stmt]int),
+		branches:   make(map[any]*[2]int),
+	}
+}
+
+// WithCoverage - record statement and branch coverage into c
+func WithCoverage(c *Coverage) Option {
+	return func(i *Interpreter) {
+		i.coverage = c
+	}
+}
+
+func (c *Coverage) add(name string, program *Program) {
+	c.files = append(c.files, coveredFile{name, program})
+}
+
+func (c *Coverage) hitStatement(stmt Stmt) {
+	c.statements[stmt]++
+}
+
+func (c *Coverage) hitBranch(node any, branch int) {
+	counts, ok := c.branches[node]
+	if !ok {
+		counts = &[2]int{}
+		c.branches[node] = counts
+	}
+	counts[branch]++
+}
+
+// fileCoverage - the counts of one program resolved to source lines
+type fileCoverage struct {
+	name       string
+	source     string
+	lines      map[int]int
+	statements int
+	covered    int
+	branches   []branchCoverage
+}
+
+type branchCoverage struct {
+	line  int
+	kind  string
+	taken [2]int
+}
+
+// labels - names of the two ways a branch point can go
+func (b branchCoverage) labels() [2]string {
+	if b.kind == "if" {
+		return [2]string{"then", "else"}
+	}
+	return [2]string{"short-circuit", "right"}
+}
+
+func (c *Coverage) resolve(file coveredFile) fileCoverage {
+	result := fileCoverage{
+		name:   file.name,
+		source: file.program.source,
+		lines:  make(map[int]int),
+	}
+
+	for stmt, line := range file.program.lines {
+		hits := c.statements[stmt]
+		result.statements++
+		if hits > 0 {
+			result.covered++
+		}
+		// several statements on a line, the line ran as often as the
+		// busiest of them
+		if current, ok := result.lines[line]; !ok || hits > current {
+			result.lines[line] = hits
+		}
+	}
+
+	inspectStmts(file.program.statements, func(node any) bool {
+		var branch branchCoverage
+		switch n := node.(type) {
+		case *If:
+			branch = branchCoverage{line: file.program.lines[n], kind: "if"}
+		case *Logical:
+			branch = branchCoverage{line: n.operator.line, kind: n.operator.lexeme}
+		default:
+			return true
+		}
+		if counts, ok := c.branches[node]; ok {
+			branch.taken = *counts
+		}
+		result.branches = append(result.branches, branch)
+		return true
+	})
+	sort.SliceStable(result.branches, func(a, b int) bool {
+		return result.branches[a].line < result.branches[b].line
+	})
+	return result
+}
+
+func (f fileCoverage) branchesCovered() (total int, covered int) {
+	for _, branch := range f.branches {
+		for _, taken := range branch.taken {
+			total++
+			if taken > 0 {
+				covered++
+			}
+		}
+	}
+	return total, covered
+}
+
+// WriteSummary - one line per file with its statement and branch coverage
+func (c *Coverage) WriteSummary(w io.Writer) {
+	var statements, covered, branches, branchesCovered int
+	for _, file := range c.files {
+		f := c.resolve(file)
+		total, taken := f.branchesCovered()
+		fmt.Fprintf(w, "%s: %s\n", f.name, coverageRatios(f.statements, f.covered, total, taken))
+		statements += f.statements
+		covered += f.covered
+		branches += total
+		branchesCovered += taken
+	}
+	if len(c.files) > 1 {
+		fmt.Fprintf(w, "total: %s\n", coverageRatios(statements, covered, branches, branchesCovered))
+	}
+}
+
+func coverageRatios(statements, covered, branches, branchesCovered int) string {
+	return fmt.Sprintf("%d/%d statements (%s), %d/%d branches (%s)",
+		covered, statements, percent(covered, statements),
+		branchesCovered, branches, percent(branchesCovered, branches))
+}
+
+func percent(part, whole int) string {
+	if whole == 0 {
+		return "100.0%"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(part)/float64(whole))
+}
+
+// WriteAnnotated - the source of every file with how often each line ran,
+// ##### marks statements that never ran; branches that never went one way
+// are listed below their line
+func (c *Coverage) WriteAnnotated(w io.Writer) {
+	for _, file := range c.files {
+		f := c.resolve(file)
+		fmt.Fprintf(w, "== %s\n", f.name)
+		for n, text := range strings.Split(strings.TrimSuffix(f.source, "\n"), "\n") {
+			line := n + 1
+			hits, ok := f.lines[line]
+			switch {
+			case !ok:
+				fmt.Fprintf(w, "%6s | %s\n", "", text)
+			case hits == 0:
+				fmt.Fprintf(w, "%6s | %s\n", "#####", text)
+			default:
+				fmt.Fprintf(w, "%6d | %s\n", hits, text)
+			}
+			for _, branch := range f.branches {
+				if branch.line == line && (branch.taken[0] == 0 || branch.taken[1] == 0) {
+					labels := branch.labels()
+					fmt.Fprintf(w, "%6s |   branch %s: %s %d, %s %d\n", "", branch.kind,
+						labels[0], branch.taken[0], labels[1], branch.taken[1])
+				}
+			}
+		}
+	}
+}
+
+// WriteLcov - tracefile in the lcov format read by genhtml and most
+// coverage services
+func (c *Coverage) WriteLcov(w io.Writer) {
+	for _, file := range c.files {
+		f := c.resolve(file)
+		fmt.Fprintf(w, "TN:\nSF:%s\n", f.name)
+
+		for n, branch := range f.branches {
+			reached := branch.taken[0]+branch.taken[1] > 0
+			for b, taken := range branch.taken {
+				if reached {
+					fmt.Fprintf(w, "BRDA:%d,%d,%d,%d\n", branch.line, n, b, taken)
+				} else {
+					fmt.Fprintf(w, "BRDA:%d,%d,%d,-\n", branch.line, n, b)
+				}
+			}
+		}
+		total, taken := f.branchesCovered()
+		fmt.Fprintf(w, "BRF:%d\nBRH:%d\n", total, taken)
+
+		lines := make([]int, 0, len(f.lines))
+		for line := range f.lines {
+			lines = append(lines, line)
+		}
+		sort.Ints(lines)
+		hit := 0
+		for _, line := range lines {
+			fmt.Fprintf(w, "DA:%d,%d\n", line, f.lines[line])
+			if f.lines[line] > 0 {
+				hit++
+			}
+		}
+		fmt.Fprintf(w, "LF:%d\nLH:%d\nend_of_record\n", len(lines), hit)
+	}
+}
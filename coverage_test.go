@@ -0,0 +1,49 @@
+package golox
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCoverage_Lcov(t *testing.T) {
+	source := `fun sign(n) {
+  if (n < 0) return -1;
+  return 1;
+}
+var ok = sign(1) == 1 or sign(2) == 2;
+`
+	path := filepath.Join(t.TempDir(), "sign.lox")
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	coverage := NewCoverage()
+	lox := NewLox(WithCoverage(coverage))
+	if _, err := lox.RunFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	coverage.WriteLcov(&out)
+	for _, expected := range []string{
+		"SF:" + path,
+		"DA:1,1",
+		"DA:2,1",
+		"DA:3,1",
+		"DA:5,1",
+		// the if never took its then branch
+		"BRDA:2,0,0,0",
+		"BRDA:2,0,1,1",
+		// or short-circuited once and never evaluated its right side
+		"BRDA:5,1,0,1",
+		"BRDA:5,1,1,0",
+		"LF:4\nLH:4\n",
+	} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("lcov output missing %q:\n%s", expected, out.String())
+		}
+	}
+}
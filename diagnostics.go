@@ -0,0 +1,175 @@
+package golox
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+)
+
+// Diagnostic severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Diagnostic codes of errors and failed tests, lint warnings use their
+// rule IDs
+const (
+	CodeSyntaxError  = "syntax-error"
+	CodeTypeError    = "type-error"
+	CodeRuntimeError = "runtime-error"
+	CodeTestFailure  = "test-failure"
+)
+
+// Diagnostic - one error or warning in a form tools can read; File is
+// empty for source without a name and Column is 0 when unknown
+type Diagnostic struct {
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column,omitempty"`
+	Message  string `json:"message"`
+}
+
+// Diagnostics - collects what the interpreter would otherwise print as
+// text, for WriteJSON or WriteSARIF. Safe for concurrent use, so a Pool
+// may share one.
+type Diagnostics struct {
+	mu          sync.Mutex
+	diagnostics []Diagnostic
+}
+
+func NewDiagnostics() *Diagnostics {
+	return &Diagnostics{}
+}
+
+// WithDiagnostics - report syntax, type and runtime errors to d instead
+// of writing them to the error output
+func WithDiagnostics(d *Diagnostics) Option {
+	return func(i *Interpreter) {
+		i.diagnostics = d
+	}
+}
+
+func (d *Diagnostics) Add(diagnostic Diagnostic) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.diagnostics = append(d.diagnostics, diagnostic)
+}
+
+// List - the diagnostics in the order they were reported
+func (d *Diagnostics) List() []Diagnostic {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return append([]Diagnostic(nil), d.diagnostics...)
+}
+
+// WriteJSON - one JSON object per line and diagnostic
+func (d *Diagnostics) WriteJSON(w io.Writer) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	for _, diagnostic := range d.List() {
+		if err := encoder.Encode(diagnostic); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SARIF 2.1.0, the subset needed for a list of results
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID string `json:"id"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation *sarifArtifactLocation `json:"artifactLocation,omitempty"`
+	Region           sarifRegion            `json:"region"`
+}
+
+type sarifArtifactLocation struct {
+	URI string `json:"uri"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine"`
+	StartColumn int `json:"startColumn,omitempty"`
+}
+
+// WriteSARIF - a SARIF log with one run of golox holding every diagnostic
+func (d *Diagnostics) WriteSARIF(w io.Writer) error {
+	diagnostics := d.List()
+	codes := make(map[string]bool)
+	results := make([]sarifResult, len(diagnostics))
+	for n, diagnostic := range diagnostics {
+		codes[diagnostic.Code] = true
+		location := sarifPhysicalLocation{
+			Region: sarifRegion{StartLine: diagnostic.Line, StartColumn: diagnostic.Column},
+		}
+		if diagnostic.File != "" {
+			location.ArtifactLocation = &sarifArtifactLocation{URI: diagnostic.File}
+		}
+		results[n] = sarifResult{
+			RuleID:    diagnostic.Code,
+			Level:     diagnostic.Severity,
+			Message:   sarifMessage{diagnostic.Message},
+			Locations: []sarifLocation{{location}},
+		}
+	}
+
+	rules := make([]sarifRule, 0, len(codes))
+	for code := range codes {
+		rules = append(rules, sarifRule{code})
+	}
+	sort.Slice(rules, func(a, b int) bool {
+		return rules[a].ID < rules[b].ID
+	})
+
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool:    sarifTool{sarifDriver{Name: "golox", Rules: rules}},
+			Results: results,
+		}},
+	})
+}
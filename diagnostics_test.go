@@ -0,0 +1,112 @@
+package golox
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDiagnosticsCompileErrors(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected []Diagnostic
+	}{
+		{"var x = 1;\nprint x +;\n$", []Diagnostic{
+			{SeverityError, CodeSyntaxError, "", 3, 1, "Unexpected character."},
+			{SeverityError, CodeSyntaxError, "", 2, 10, "Expect expression."},
+		}},
+		{"var s: string = 1;", []Diagnostic{
+			{SeverityError, CodeTypeError, "", 1, 5, "Cannot assign number to 's' of type string."},
+		}},
+		{"print \"a\nb", []Diagnostic{
+			{SeverityError, CodeSyntaxError, "", 2, 0, "Unterminated string."},
+			{SeverityError, CodeSyntaxError, "", 2, 2, "Expect expression."},
+		}},
+	}
+	for _, tt := range tests {
+		var errOut bytes.Buffer
+		diagnostics := NewDiagnostics()
+		if _, err := Compile(tt.source, WithErrorOutput(&errOut), WithDiagnostics(diagnostics), WithTypeCheck()); err != ErrSyntax {
+			t.Errorf("%q: Compile error %v, expected ErrSyntax", tt.source, err)
+		}
+		if result := diagnostics.List(); !reflect.DeepEqual(result, tt.expected) {
+			t.Errorf("%q: diagnostics %v, expected %v", tt.source, result, tt.expected)
+		}
+		if errOut.Len() > 0 {
+			t.Errorf("%q: error output %q, expected none", tt.source, errOut.String())
+		}
+	}
+}
+
+func TestDiagnosticsRuntimeError(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "script.lox")
+	if err := os.WriteFile(path, []byte("fun negate(x) {\n  return -x;\n}\nprint 1;\nprint negate(\"a\");\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	diagnostics := NewDiagnostics()
+	status, err := NewLox(WithOutput(&out), WithDiagnostics(diagnostics)).RunFile(path)
+	if err != nil || status != 70 {
+		t.Fatalf("RunFile %d, %v", status, err)
+	}
+	expected := []Diagnostic{{SeverityError, CodeRuntimeError, path, 2, 10, "Operand must be a number"}}
+	if result := diagnostics.List(); !reflect.DeepEqual(result, expected) {
+		t.Errorf("diagnostics %v, expected %v", result, expected)
+	}
+	if out.String() != "1\n" {
+		t.Errorf("output %q", out.String())
+	}
+}
+
+func TestDiagnosticsWriteJSON(t *testing.T) {
+	diagnostics := NewDiagnostics()
+	diagnostics.Add(Diagnostic{SeverityError, CodeSyntaxError, "a.lox", 1, 2, "Expect ';' after value."})
+	diagnostics.Add(LintWarning{3, RuleUnusedVariable, "Local variable 'x' is never used."}.Diagnostic("b.lox"))
+
+	var out bytes.Buffer
+	if err := diagnostics.WriteJSON(&out); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"severity":"error","code":"syntax-error","file":"a.lox","line":1,"column":2,"message":"Expect ';' after value."}` + "\n" +
+		`{"severity":"warning","code":"unused-variable","file":"b.lox","line":3,"message":"Local variable 'x' is never used."}` + "\n"
+	if out.String() != expected {
+		t.Errorf("WriteJSON result\n%s\nexpected\n%s", out.String(), expected)
+	}
+}
+
+func TestDiagnosticsWriteSARIF(t *testing.T) {
+	diagnostics := NewDiagnostics()
+	diagnostics.Add(Diagnostic{SeverityError, CodeRuntimeError, "a.lox", 4, 9, "Division by zero."})
+	diagnostics.Add(Diagnostic{SeverityWarning, RuleWrongArity, "", 2, 0, "'f' expects 1 argument but got 2."})
+
+	var out bytes.Buffer
+	if err := diagnostics.WriteSARIF(&out); err != nil {
+		t.Fatal(err)
+	}
+	var log sarifLog
+	if err := json.Unmarshal(out.Bytes(), &log); err != nil {
+		t.Fatal(err)
+	}
+	if log.Version != "2.1.0" || len(log.Runs) != 1 {
+		t.Fatalf("SARIF log %+v", log)
+	}
+	run := log.Runs[0]
+	if rules := run.Tool.Driver.Rules; !reflect.DeepEqual(rules, []sarifRule{{CodeRuntimeError}, {RuleWrongArity}}) {
+		t.Errorf("rules %v", rules)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("results %+v", run.Results)
+	}
+	first := run.Results[0]
+	location := first.Locations[0].PhysicalLocation
+	if first.RuleID != CodeRuntimeError || first.Level != "error" || first.Message.Text != "Division by zero." ||
+		location.ArtifactLocation.URI != "a.lox" || location.Region != (sarifRegion{4, 9}) {
+		t.Errorf("first result %+v", first)
+	}
+	if second := run.Results[1]; second.Level != "warning" || second.Locations[0].PhysicalLocation.ArtifactLocation != nil {
+		t.Errorf("second result %+v", second)
+	}
+}
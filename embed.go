@@ -0,0 +1,98 @@
+package golox
+
+import (
+	"context"
+	"fmt"
+)
+
+// Callable - a global Lox function (or native) the host can call after
+// the script defining it has run
+type Callable struct {
+	name        string
+	interpreter *Interpreter
+	callable    LoxCallable
+}
+
+// CallError - the host could not call a function: it is undefined, not
+// callable, got the wrong number of arguments or a native failed
+type CallError struct {
+	Name    string
+	Message string
+}
+
+func (e *CallError) Error() string {
+	return fmt.Sprintf("golox: call %s: %s", e.Name, e.Message)
+}
+
+// Callable - look up a global function by name
+func (l *Lox) Callable(name string) (*Callable, error) {
+	value, ok := l.Global(name)
+	if !ok {
+		return nil, &CallError{name, "undefined"}
+	}
+	callable, ok := value.(LoxCallable)
+	if !ok {
+		return nil, &CallError{name, fmt.Sprintf("%s is not callable", typeName(value))}
+	}
+	return &Callable{
+		name:        name,
+		interpreter: l.interpreter,
+		callable:    callable,
+	}, nil
+}
+
+// Call - look up and call a global function in one step
+func (l *Lox) Call(name string, args ...any) (any, error) {
+	callable, err := l.Callable(name)
+	if err != nil {
+		return nil, err
+	}
+	return callable.Call(args...)
+}
+
+func (c *Callable) Arity() int {
+	return c.callable.arity()
+}
+
+// Call - arguments are converted with ToLox, the result is a Lox value to
+// convert with FromLox; errors raised by the script come back as
+// RuntimeError or ExitError and are not printed
+func (c *Callable) Call(args ...any) (any, error) {
+	return c.CallContext(context.Background(), args...)
+}
+
+// CallContext - Call that stops with a CancelError once ctx is done
+func (c *Callable) CallContext(ctx context.Context, args ...any) (any, error) {
+	arity := c.callable.arity()
+	maxArity := arity
+	if f, ok := c.callable.(optionalArity); ok {
+		maxArity += f.optional()
+	}
+	if len(args) < arity || len(args) > maxArity {
+		return nil, &CallError{c.name,
+			fmt.Sprintf("expected %d arguments but got %d", arity, len(args))}
+	}
+
+	arguments := make([]any, len(args))
+	for n, arg := range args {
+		arguments[n] = ToLox(arg)
+	}
+
+	previous := c.interpreter.ctx
+	c.interpreter.ctx = ctx
+	defer func() { c.interpreter.ctx = previous }()
+
+	if profiler := c.interpreter.profiler; profiler != nil {
+		profiler.enterCall(c.callable, 0)
+		defer profiler.leaveCall()
+	}
+	value, err := c.callable.call(c.interpreter, arguments)
+	if err != nil {
+		switch err.(type) {
+		case RuntimeError, ExitError, CancelError:
+			return nil, err
+		}
+		return nil, &CallError{c.name, err.Error()}
+	}
+	return value, nil
+}
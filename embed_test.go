@@ -0,0 +1,43 @@
+package golox
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLox_Call(t *testing.T) {
+	lox := NewLox()
+	err := lox.Run(`
+		fun handler(request) {
+			if (request.Path == "/fail") return nil + 1;
+			return "handled " + request.Path;
+		}
+		var notAFunction = 1;
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type request struct{ Path string }
+	result, err := lox.Call("handler", request{"/home"})
+	if err != nil || result != "handled /home" {
+		t.Errorf("Call(handler) result %v (%v), expected handled /home", result, err)
+	}
+
+	_, err = lox.Call("handler", request{"/fail"})
+	var runtimeErr RuntimeError
+	if !errors.As(err, &runtimeErr) || runtimeErr.Token.Line() != 3 {
+		t.Errorf("Call(handler) error %v, expected a RuntimeError on line 3", err)
+	}
+
+	for _, name := range []string{"missing", "notAFunction"} {
+		var callErr *CallError
+		if _, err := lox.Call(name); !errors.As(err, &callErr) {
+			t.Errorf("Call(%s) error %v, expected a CallError", name, err)
+		}
+	}
+	var callErr *CallError
+	if _, err := lox.Call("handler"); !errors.As(err, &callErr) {
+		t.Errorf("Call(handler) without arguments error %v, expected a CallError", err)
+	}
+}
@@ -0,0 +1,17 @@
+package golox
+
+import "fmt"
+
+// ExitError - raised by the exit native, unwinds the interpreter the same
+// way ReturnValue unwinds a function and hands the status to the host
+type ExitError struct {
+	Code int
+}
+
+func NewExitError(code int) ExitError {
+	return ExitError{code}
+}
+
+func (e ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
@@ -9,9 +9,14 @@ type ExprVisitor interface {
   visitAssignExpr(expr *Assign) (any, error)
   visitBinaryExpr(expr *Binary) (any, error)
   visitCallExpr(expr *Call) (any, error)
+  visitCompoundAssignExpr(expr *CompoundAssign) (any, error)
+  visitConditionalExpr(expr *Conditional) (any, error)
+  visitGetExpr(expr *Get) (any, error)
   visitGroupingExpr(expr *Grouping) (any, error)
+  visitIncrementExpr(expr *Increment) (any, error)
   visitLiteralExpr(expr *Literal) (any, error)
   visitLogicalExpr(expr *Logical) (any, error)
+  visitSetExpr(expr *Set) (any, error)
   visitUnaryExpr(expr *Unary) (any, error)
   visitVariableExpr(expr *Variable) (any, error)
 }
@@ -68,6 +73,58 @@ func (expr *Call) Accept(visitor ExprVisitor) (any, error) {
   return visitor.visitCallExpr(expr)
 }
 
+type CompoundAssign struct {
+  target Expr
+  operator *Token
+  value Expr
+}
+
+func NewCompoundAssign(target Expr, operator *Token, value Expr) *CompoundAssign {
+  return &CompoundAssign{
+    target: target,
+    operator: operator,
+    value: value,
+  }
+}
+
+func (expr *CompoundAssign) Accept(visitor ExprVisitor) (any, error) {
+  return visitor.visitCompoundAssignExpr(expr)
+}
+
+type Conditional struct {
+  condition Expr
+  thenBranch Expr
+  elseBranch Expr
+}
+
+func NewConditional(condition Expr, thenBranch Expr, elseBranch Expr) *Conditional {
+  return &Conditional{
+    condition: condition,
+    thenBranch: thenBranch,
+    elseBranch: elseBranch,
+  }
+}
+
+func (expr *Conditional) Accept(visitor ExprVisitor) (any, error) {
+  return visitor.visitConditionalExpr(expr)
+}
+
+type Get struct {
+  object Expr
+  name *Token
+}
+
+func NewGet(object Expr, name *Token) *Get {
+  return &Get{
+    object: object,
+    name: name,
+  }
+}
+
+func (expr *Get) Accept(visitor ExprVisitor) (any, error) {
+  return visitor.visitGetExpr(expr)
+}
+
 type Grouping struct {
   expression Expr
 }
@@ -82,6 +139,24 @@ func (expr *Grouping) Accept(visitor ExprVisitor) (any, error) {
   return visitor.visitGroupingExpr(expr)
 }
 
+type Increment struct {
+  target Expr
+  operator *Token
+  prefix bool
+}
+
+func NewIncrement(target Expr, operator *Token, prefix bool) *Increment {
+  return &Increment{
+    target: target,
+    operator: operator,
+    prefix: prefix,
+  }
+}
+
+func (expr *Increment) Accept(visitor ExprVisitor) (any, error) {
+  return visitor.visitIncrementExpr(expr)
+}
+
 type Literal struct {
   value any
 }
@@ -114,6 +189,24 @@ func (expr *Logical) Accept(visitor ExprVisitor) (any, error) {
   return visitor.visitLogicalExpr(expr)
 }
 
+type Set struct {
+  object Expr
+  name *Token
+  value Expr
+}
+
+func NewSet(object Expr, name *Token, value Expr) *Set {
+  return &Set{
+    object: object,
+    name: name,
+    value: value,
+  }
+}
+
+func (expr *Set) Accept(visitor ExprVisitor) (any, error) {
+  return visitor.visitSetExpr(expr)
+}
+
 type Unary struct {
   operator *Token
   right Expr
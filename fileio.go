@@ -0,0 +1,264 @@
+package golox
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// WithFileRoot - enable the file natives, confined to the root directory
+// paths given by scripts are relative to root and may not leave it,
+// neither through '..' nor through symbolic links
+func WithFileRoot(root string) Option {
+	return func(i *Interpreter) {
+		i.fileRoot = root
+	}
+}
+
+func defineFileNatives(globals *Environment) {
+	globals.define("readFile", NewNativeFunction("readFile", 1, nativeReadFile))
+	globals.define("writeFile", NewNativeFunction("writeFile", 2, nativeWriteFile))
+	globals.define("appendFile", NewNativeFunction("appendFile", 2, nativeAppendFile))
+	globals.define("listDir", NewNativeFunction("listDir", 1, nativeListDir))
+	globals.define("exists", NewNativeFunction("exists", 1, nativeExists))
+	globals.define("openFile", NewNativeFunction("openFile", 1, nativeOpenFile))
+	globals.define("readLine", NewNativeFunction("readLine", 1, nativeReadLine))
+	globals.define("closeFile", NewNativeFunction("closeFile", 1, nativeCloseFile))
+}
+
+// loxFile - handle returned by openFile for line by line reading
+type loxFile struct {
+	name   string
+	file   io.Closer
+	reader *bufio.Reader
+}
+
+func (f *loxFile) String() string {
+	return fmt.Sprintf("<file %s>", f.name)
+}
+
+// sandboxPath - map a script path onto the file system below the root
+func (i *Interpreter) sandboxPath(argument any) (string, error) {
+	path, ok := argument.(string)
+	if !ok {
+		return "", fmt.Errorf("Path must be a string.")
+	}
+	if i.fileRoot == "" {
+		return "", fmt.Errorf("File access is disabled.")
+	}
+	if filepath.IsAbs(path) {
+		return "", fmt.Errorf("Path '%s' must be relative to the sandbox root.", path)
+	}
+
+	root, err := filepath.Abs(i.fileRoot)
+	if err != nil {
+		return "", fmt.Errorf("Invalid sandbox root: %s", err)
+	}
+	root, err = filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", fmt.Errorf("Invalid sandbox root: %s", err)
+	}
+
+	full := filepath.Join(root, path)
+	if !isWithin(root, full) {
+		return "", fmt.Errorf("Path '%s' is outside the sandbox root.", path)
+	}
+	real, err := evalExistingSymlinks(full)
+	if err != nil {
+		return "", err
+	}
+	if !isWithin(root, real) {
+		return "", fmt.Errorf("Path '%s' is outside the sandbox root.", path)
+	}
+	return real, nil
+}
+
+// maxSymlinks - links followed for one path before giving up
+const maxSymlinks = 40
+
+// evalExistingSymlinks - resolve links along the part of path that exists
+// so that files about to be created are checked through their directory;
+// a dangling link is followed to the target a file would be created at
+func evalExistingSymlinks(path string) (string, error) {
+	return evalSymlinks(path, 0)
+}
+
+func evalSymlinks(path string, links int) (string, error) {
+	real, err := filepath.EvalSymlinks(path)
+	if err == nil {
+		return real, nil
+	}
+	if !os.IsNotExist(err) {
+		return "", err
+	}
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path, nil
+	}
+	realParent, err := evalSymlinks(parent, links)
+	if err != nil {
+		return "", err
+	}
+	real = filepath.Join(realParent, filepath.Base(path))
+
+	info, err := os.Lstat(real)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		return real, nil
+	}
+	if links >= maxSymlinks {
+		return "", fmt.Errorf("Too many symbolic links in '%s'.", path)
+	}
+	target, err := os.Readlink(real)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(realParent, target)
+	}
+	return evalSymlinks(target, links+1)
+}
+
+func isWithin(root string, path string) bool {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// fileError - drop the resolved host path from os errors
+func fileError(argument any, err error) error {
+	if pathErr, ok := err.(*os.PathError); ok {
+		err = pathErr.Err
+	}
+	return fmt.Errorf("File '%v': %s.", argument, err)
+}
+
+func nativeReadFile(i *Interpreter, arguments []any) (any, error) {
+	path, err := i.sandboxPath(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+	bytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fileError(arguments[0], err)
+	}
+	return string(bytes), nil
+}
+
+func nativeWriteFile(i *Interpreter, arguments []any) (any, error) {
+	return nil, i.writeFile(arguments, os.O_WRONLY|os.O_CREATE|os.O_TRUNC)
+}
+
+func nativeAppendFile(i *Interpreter, arguments []any) (any, error) {
+	return nil, i.writeFile(arguments, os.O_WRONLY|os.O_CREATE|os.O_APPEND)
+}
+
+func (i *Interpreter) writeFile(arguments []any, flag int) error {
+	path, err := i.sandboxPath(arguments[0])
+	if err != nil {
+		return err
+	}
+	content, ok := arguments[1].(string)
+	if !ok {
+		return fmt.Errorf("File content must be a string.")
+	}
+	file, err := os.OpenFile(path, flag, 0644)
+	if err != nil {
+		return fileError(arguments[0], err)
+	}
+	if _, err := file.WriteString(content); err != nil {
+		file.Close()
+		return fileError(arguments[0], err)
+	}
+	if err := file.Close(); err != nil {
+		return fileError(arguments[0], err)
+	}
+	return nil
+}
+
+func nativeListDir(i *Interpreter, arguments []any) (any, error) {
+	path, err := i.sandboxPath(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fileError(arguments[0], err)
+	}
+	names := []any{}
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	return NewLoxList(names), nil
+}
+
+func nativeExists(i *Interpreter, arguments []any) (any, error) {
+	path, err := i.sandboxPath(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+	_, err = os.Stat(path)
+	if err == nil {
+		return true, nil
+	}
+	if os.IsNotExist(err) {
+		return false, nil
+	}
+	return nil, fileError(arguments[0], err)
+}
+
+func nativeOpenFile(i *Interpreter, arguments []any) (any, error) {
+	path, err := i.sandboxPath(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fileError(arguments[0], err)
+	}
+	return &loxFile{
+		name:   arguments[0].(string),
+		file:   file,
+		reader: bufio.NewReader(file),
+	}, nil
+}
+
+// readLine(file) - next line without its line ending, nil at end of file
+func nativeReadLine(i *Interpreter, arguments []any) (any, error) {
+	file, ok := arguments[0].(*loxFile)
+	if !ok {
+		return nil, fmt.Errorf("Can only read lines from an open file.")
+	}
+	if file.reader == nil {
+		return nil, fmt.Errorf("File '%s' is closed.", file.name)
+	}
+	line, err := file.reader.ReadString('\n')
+	if err == io.EOF {
+		if line == "" {
+			return nil, nil
+		}
+	} else if err != nil {
+		return nil, fileError(file.name, err)
+	}
+	line = strings.TrimSuffix(line, "\n")
+	return strings.TrimSuffix(line, "\r"), nil
+}
+
+func nativeCloseFile(i *Interpreter, arguments []any) (any, error) {
+	file, ok := arguments[0].(*loxFile)
+	if !ok {
+		return nil, fmt.Errorf("Can only close an open file.")
+	}
+	if file.reader == nil {
+		return nil, nil
+	}
+	file.reader = nil
+	if err := file.file.Close(); err != nil {
+		return nil, fileError(file.name, err)
+	}
+	return nil, nil
+}
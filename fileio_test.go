@@ -0,0 +1,138 @@
+package golox
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestInterpreter_SandboxPath(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Mkdir(filepath.Join(root, "data"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(root, "data", "link")); err != nil {
+		t.Skip("symlinks not supported:", err)
+	}
+	// dangling links, creating a file through them creates their target
+	if err := os.Symlink(filepath.Join(outside, "pwned"), filepath.Join(root, "evil")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("evil", filepath.Join(root, "chain")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("data/created.txt", filepath.Join(root, "inside")); err != nil {
+		t.Fatal(err)
+	}
+
+	interpreter := NewInterpreter(NewLox(), WithFileRoot(root))
+	tests := []struct {
+		path    string
+		allowed bool
+	}{
+		{"a.txt", true},
+		{"data/new/b.txt", true},
+		{"data/../a.txt", true},
+		{"../a.txt", false},
+		{"data/../../a.txt", false},
+		{"data/link/a.txt", false},
+		{"data/link", false},
+		{"evil", false},
+		{"chain", false},
+		{"inside", true},
+		{filepath.Join(outside, "a.txt"), false},
+	}
+	for _, tt := range tests {
+		_, err := interpreter.sandboxPath(tt.path)
+		if tt.allowed && err != nil {
+			t.Errorf("sandboxPath(%q) error %v, expected allowed", tt.path, err)
+		}
+		if !tt.allowed && err == nil {
+			t.Errorf("sandboxPath(%q) allowed, expected rejected", tt.path)
+		}
+	}
+
+	_, err := nativeWriteFile(interpreter, []any{"evil", "escaped"})
+	if err == nil {
+		t.Errorf("writeFile through a dangling link allowed, expected rejected")
+	}
+	if _, err := os.Lstat(filepath.Join(outside, "pwned")); !os.IsNotExist(err) {
+		t.Errorf("writeFile through a dangling link created its target outside the root")
+	}
+
+	_, err = NewInterpreter(NewLox()).sandboxPath("a.txt")
+	if err == nil {
+		t.Errorf("sandboxPath without root allowed, expected file access disabled")
+	}
+}
+
+func TestFileNatives(t *testing.T) {
+	tests := []struct {
+		name   string
+		files  map[string]string
+		source string
+		// output - printed lines, then the runtime error if there is one
+		output string
+	}{
+		{"write and read", nil, `writeFile("a.txt", "hello");
+print readFile("a.txt");`, "hello\n"},
+		{"write truncates", nil, `writeFile("a.txt", "long content");
+writeFile("a.txt", "short");
+print readFile("a.txt");`, "short\n"},
+		{"append", map[string]string{"log.txt": "zero;"}, `appendFile("log.txt", "one;");
+appendFile("log.txt", "two;");
+print readFile("log.txt");`, "zero;one;two;\n"},
+		{"exists", nil, `print exists("a.txt");
+writeFile("a.txt", "");
+print exists("a.txt");`, "false\ntrue\n"},
+		{"list dir", nil, `writeFile("b.txt", "");
+writeFile("a.txt", "");
+print listDir(".");`, "[\"a.txt\", \"b.txt\"]\n"},
+		{"read lines", map[string]string{"lines.txt": "first\r\nsecond\nlast"}, `var file = openFile("lines.txt");
+var line = readLine(file);
+while (line != nil) {
+  print line;
+  line = readLine(file);
+}
+print readLine(file);
+closeFile(file);`, "first\nsecond\nlast\nnil\n"},
+		{"read after close", nil, `writeFile("a.txt", "x\n");
+var file = openFile("a.txt");
+closeFile(file);
+closeFile(file);
+print readLine(file);`, "File 'a.txt' is closed.\n[line 5]\n"},
+		{"read missing", nil, `print readFile("missing.txt");`,
+			"File 'missing.txt': no such file or directory.\n[line 1]\n"},
+		{"read outside", nil, `print readFile("../secret.txt");`,
+			"Path '../secret.txt' is outside the sandbox root.\n[line 1]\n"},
+		{"write outside", nil, `writeFile("/tmp/a.txt", "x");`,
+			"Path '/tmp/a.txt' must be relative to the sandbox root.\n[line 1]\n"},
+		{"open outside", nil, `openFile("../a.txt");`,
+			"Path '../a.txt' is outside the sandbox root.\n[line 1]\n"},
+		{"write a number", nil, `writeFile("a.txt", 1);`,
+			"File content must be a string.\n[line 1]\n"},
+		{"read lines of a string", nil, `readLine("a.txt");`,
+			"Can only read lines from an open file.\n[line 1]\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := t.TempDir()
+			for name, content := range tt.files {
+				if err := os.WriteFile(filepath.Join(root, name), []byte(content), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			var out bytes.Buffer
+			lox := NewLox(WithFileRoot(root), WithOutput(&out), WithErrorOutput(&out))
+			err := lox.Run(tt.source)
+			if _, ok := err.(RuntimeError); err != nil && !ok {
+				t.Fatalf("Run error %T %v, expected nil or a RuntimeError", err, err)
+			}
+			if out.String() != tt.output {
+				t.Errorf("output %q, expected %q", out.String(), tt.output)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package golox
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+)
+
+// formatFloat - shortest representation that reads back as the same float64
+// plain notation for 1e-6 <= |v| < 1e21, exponent notation outside of it
+func formatFloat(value float64) string {
+	switch {
+	case math.IsNaN(value):
+		return "NaN"
+	case math.IsInf(value, 1):
+		return "Infinity"
+	case math.IsInf(value, -1):
+		return "-Infinity"
+	case value == 0:
+		if math.Signbit(value) {
+			return "-0"
+		}
+		return "0"
+	}
+
+	abs := math.Abs(value)
+	if abs >= 1e-6 && abs < 1e21 {
+		return strconv.FormatFloat(value, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(value, 'e', -1, 64)
+}
+
+// formatSpec - [flags][width][.precision]verb, e.g. ".2f", "08.3e", "x"
+var formatSpec = regexp.MustCompile(`^([-+ 0]*)([0-9]*)(\.[0-9]+)?([feEgGdxXobs])$`)
+
+// format(value, spec) - explicit precision and base for numbers
+func nativeFormat(i *Interpreter, arguments []any) (any, error) {
+	spec, ok := arguments[1].(string)
+	if !ok {
+		return nil, fmt.Errorf("Format spec must be a string.")
+	}
+	parts := formatSpec.FindStringSubmatch(spec)
+	if parts == nil {
+		return nil, fmt.Errorf("Invalid format spec '%s'.", spec)
+	}
+
+	value := arguments[0]
+	switch verb := parts[4]; verb {
+	case "s":
+		return fmt.Sprintf("%"+spec, i.stringify(value)), nil
+	case "d", "x", "X", "o", "b":
+		n, ok := value.(int64)
+		if !ok {
+			return nil, fmt.Errorf("Format '%s' expects an integer.", verb)
+		}
+		if parts[3] != "" {
+			return nil, fmt.Errorf("Format '%s' does not take a precision.", verb)
+		}
+		return fmt.Sprintf("%"+spec, n), nil
+	default:
+		if !isNumber(value) {
+			return nil, fmt.Errorf("Format '%s' expects a number.", verb)
+		}
+		return fmt.Sprintf("%"+spec, toFloat(value)), nil
+	}
+}
@@ -0,0 +1,83 @@
+package golox
+
+import (
+	"math"
+	"testing"
+)
+
+func TestInterpreter_Stringify(t *testing.T) {
+	tests := []struct {
+		value    any
+		expected string
+	}{
+		{int64(42), "42"},
+		{int64(-9223372036854775808), "-9223372036854775808"},
+		{1.0 / 3.0, "0.3333333333333333"},
+		{0.001, "0.001"},
+		{0.30000000000000004, "0.30000000000000004"},
+		{2.5, "2.5"},
+		{3.0, "3"},
+		{math.Copysign(0, -1), "-0"},
+		{0.0, "0"},
+		{math.NaN(), "NaN"},
+		{math.Inf(1), "Infinity"},
+		{math.Inf(-1), "-Infinity"},
+		{123456789012345680000.0, "123456789012345680000"},
+		{1e21, "1e+21"},
+		{0.000001, "0.000001"},
+		{0.0000001, "1e-07"},
+		{-1.5e-300, "-1.5e-300"},
+		{nil, "nil"},
+		{true, "true"},
+		{"text", "text"},
+	}
+
+	interpreter := NewInterpreter(NewLox())
+	for _, tt := range tests {
+		result := interpreter.stringify(tt.value)
+		if result != tt.expected {
+			t.Errorf("stringify(%#v) result %s, expected %s",
+				tt.value,
+				result,
+				tt.expected,
+			)
+		}
+	}
+}
+
+func TestNativeFormat(t *testing.T) {
+	tests := []struct {
+		value    any
+		spec     string
+		expected string
+		err      string
+	}{
+		{1.0 / 3.0, ".2f", "0.33", ""},
+		{int64(2), ".3f", "2.000", ""},
+		{12345.678, ".3e", "1.235e+04", ""},
+		{0.5, "8.2f", "    0.50", ""},
+		{int64(255), "x", "ff", ""},
+		{int64(5), "08b", "00000101", ""},
+		{int64(-7), "+d", "-7", ""},
+		{"go", "-4s", "go  ", ""},
+		{1.5, "d", "", "Format 'd' expects an integer."},
+		{"x", ".2f", "", "Format 'f' expects a number."},
+		{int64(1), "%d", "", "Invalid format spec '%d'."},
+	}
+
+	interpreter := NewInterpreter(NewLox())
+	for _, tt := range tests {
+		result, err := nativeFormat(interpreter, []any{tt.value, tt.spec})
+		if tt.err != "" {
+			if err == nil || err.Error() != tt.err {
+				t.Errorf("format(%v, %q) error %v, expected %s",
+					tt.value, tt.spec, err, tt.err)
+			}
+			continue
+		}
+		if err != nil || result != tt.expected {
+			t.Errorf("format(%v, %q) result %v, expected %s",
+				tt.value, tt.spec, result, tt.expected)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package golox
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the .golden files of scripts without expectations")
+
+// Expectations embedded in a script, following Crafting Interpreters:
+//
+//	print 1 + 2; // expect: 3
+//	print -"a";  // expect runtime error: Operand must be a number.
+//	print ;      // Error at ';': Expect expression.
+//	             // [line 7] Error at end: Expect '}' after block.
+//
+// Runtime and syntax errors without an explicit line are expected on the
+// line of the comment. A script without any expectation is compared
+// against the .golden file next to it instead, holding the printed output
+// followed by the error output after a goldenErrorMarker line.
+var (
+	expectOutputPattern  = regexp.MustCompile(`// expect: ?(.*)`)
+	expectRuntimePattern = regexp.MustCompile(`// expect runtime error: (.+)`)
+	expectErrorPattern   = regexp.MustCompile(`// (\[line (\d+)\] )?(Error.*)`)
+)
+
+const goldenErrorMarker = "-- error output --"
+
+func TestGoldenScripts(t *testing.T) {
+	runGoldenDir(t, "test")
+}
+
+// runGoldenDir - run every .lox and .txt script in dir as a subtest
+func runGoldenDir(t *testing.T, dir string) {
+	var scripts []string
+	for _, pattern := range []string{"*.lox", "*.txt"} {
+		matches, err := filepath.Glob(filepath.Join(dir, pattern))
+		if err != nil {
+			t.Fatal(err)
+		}
+		scripts = append(scripts, matches...)
+	}
+	sort.Strings(scripts)
+	if len(scripts) == 0 {
+		t.Fatalf("no scripts in %s", dir)
+	}
+
+	for _, script := range scripts {
+		script := script
+		t.Run(filepath.Base(script), func(t *testing.T) {
+			runGoldenScript(t, script)
+		})
+	}
+}
+
+func runGoldenScript(t *testing.T, path string) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// scripts are type checked so type errors can be expected too
+	var stdout, stderr bytes.Buffer
+	lox := NewLox(
+		WithOutput(&stdout),
+		WithErrorOutput(&stderr),
+		WithFileRoot(filepath.Dir(path)),
+		WithSeed(1),
+		WithTypeCheck(),
+	)
+	lox.Run(string(source))
+
+	wantOut, wantErr, embedded := parseExpectations(string(source))
+	if !embedded {
+		golden := strings.TrimSuffix(path, filepath.Ext(path)) + ".golden"
+		actual := stdout.String()
+		if stderr.Len() > 0 {
+			actual += goldenErrorMarker + "\n" + stderr.String()
+		}
+		if *update {
+			if err := os.WriteFile(golden, []byte(actual), 0644); err != nil {
+				t.Fatal(err)
+			}
+			return
+		}
+		expected, err := os.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("%s has no expectations and no golden file, run go test -update: %v", path, err)
+		}
+		if diff := diffLines(splitLines(string(expected)), splitLines(actual)); diff != "" {
+			t.Errorf("%s differs from %s (-want +got):\n%s", path, golden, diff)
+		}
+		return
+	}
+
+	if diff := diffLines(wantOut, splitLines(stdout.String())); diff != "" {
+		t.Errorf("output of %s (-want +got):\n%s", path, diff)
+	}
+	if diff := diffLines(wantErr, splitLines(stderr.String())); diff != "" {
+		t.Errorf("errors of %s (-want +got):\n%s", path, diff)
+	}
+}
+
+// parseExpectations - expected output and error lines, embedded reports
+// whether the script has any expectation at all
+func parseExpectations(source string) (output []string, errors []string, embedded bool) {
+	for n, line := range strings.Split(source, "\n") {
+		lineNo := n + 1
+		if m := expectOutputPattern.FindStringSubmatch(line); m != nil {
+			output = append(output, m[1])
+		} else if m := expectRuntimePattern.FindStringSubmatch(line); m != nil {
+			errors = append(errors, m[1], fmt.Sprintf("[line %d]", lineNo))
+		} else if m := expectErrorPattern.FindStringSubmatch(line); m != nil {
+			if m[2] != "" {
+				lineNo, _ = strconv.Atoi(m[2])
+			}
+			errors = append(errors, fmt.Sprintf("[line %d] %s", lineNo, m[3]))
+		} else {
+			continue
+		}
+		embedded = true
+	}
+	return output, errors, embedded
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// diffLines - line diff of want and got built on their longest common
+// subsequence, empty when they are equal
+func diffLines(want, got []string) string {
+	// lcs[i][j] - length of the common subsequence of want[i:] and got[j:]
+	lcs := make([][]int, len(want)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(got)+1)
+	}
+	for i := len(want) - 1; i >= 0; i-- {
+		for j := len(got) - 1; j >= 0; j-- {
+			if want[i] == got[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var sb strings.Builder
+	changed := false
+	i, j := 0, 0
+	for i < len(want) || j < len(got) {
+		switch {
+		case i < len(want) && j < len(got) && want[i] == got[j]:
+			fmt.Fprintf(&sb, "  %s\n", want[i])
+			i++
+			j++
+		case i < len(want) && (j == len(got) || lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(&sb, "- %s\n", want[i])
+			changed = true
+			i++
+		default:
+			fmt.Fprintf(&sb, "+ %s\n", got[j])
+			changed = true
+			j++
+		}
+	}
+	if !changed {
+		return ""
+	}
+	return sb.String()
+}
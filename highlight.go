@@ -0,0 +1,114 @@
+package golox
+
+import (
+	"html"
+	"io"
+	"strings"
+)
+
+// HighlightFormat - how Highlight marks up the source
+type HighlightFormat int
+
+const (
+	// HighlightANSI - terminal colors
+	HighlightANSI HighlightFormat = iota
+	// HighlightHTML - a <pre> element with a span and class per token,
+	// lox-keyword, lox-string, lox-number and lox-comment
+	HighlightHTML
+)
+
+var highlightColors = map[string]string{
+	"keyword": "\x1b[35m",
+	"string":  "\x1b[32m",
+	"number":  "\x1b[36m",
+	"comment": "\x1b[90m",
+}
+
+const ansiReset = "\x1b[0m"
+
+// highlightClass - the class of a token kind, "" for the ones left plain
+func highlightClass(kind TokenType) string {
+	switch {
+	case kind >= TkAnd && kind <= TkWhile:
+		return "keyword"
+	case kind == TkString:
+		return "string"
+	case kind == TkNumber:
+		return "number"
+	}
+	return ""
+}
+
+// Highlight - write source with its tokens marked up for format; the
+// text between tokens is copied as it is, apart from comments, so the
+// output shows exactly the source even when it does not scan
+func Highlight(w io.Writer, source string, format HighlightFormat) error {
+	h := &highlighter{format: format}
+	if format == HighlightHTML {
+		h.sb.WriteString(`<pre class="lox">`)
+	}
+
+	tokens, _ := ScanTokens(source, WithErrorOutput(io.Discard))
+	position := 0
+	for _, token := range tokens {
+		if token.kind == TkEof || token.offset < position {
+			continue
+		}
+		h.gap(source[position:token.offset])
+		h.write(highlightClass(token.kind), token.lexeme)
+		position = token.offset + len(token.lexeme)
+	}
+	h.gap(source[position:])
+
+	if format == HighlightHTML {
+		h.sb.WriteString("</pre>\n")
+	}
+	_, err := io.WriteString(w, h.sb.String())
+	return err
+}
+
+type highlighter struct {
+	format HighlightFormat
+	sb     strings.Builder
+}
+
+// gap - whitespace, comments and whatever did not scan
+func (h *highlighter) gap(text string) {
+	for text != "" {
+		start := strings.Index(text, "//")
+		if start == -1 {
+			h.write("", text)
+			return
+		}
+		end := strings.IndexByte(text[start:], '\n')
+		if end == -1 {
+			end = len(text)
+		} else {
+			end += start
+		}
+		h.write("", text[:start])
+		h.write("comment", text[start:end])
+		text = text[end:]
+	}
+}
+
+func (h *highlighter) write(class string, text string) {
+	if text == "" {
+		return
+	}
+	switch h.format {
+	case HighlightHTML:
+		text = html.EscapeString(text)
+		if class == "" {
+			h.sb.WriteString(text)
+			return
+		}
+		h.sb.WriteString(`<span class="lox-` + class + `">` + text + "</span>")
+	default:
+		if class == "" {
+			h.sb.WriteString(text)
+			return
+		}
+		h.sb.WriteString(highlightColors[class] + text + ansiReset)
+	}
+}
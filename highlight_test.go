@@ -0,0 +1,29 @@
+package golox
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestHighlight(t *testing.T) {
+	source := "var s = \"<b>\"; // a//b\nprint s + 1 @\n"
+	tests := []struct {
+		format   HighlightFormat
+		expected string
+	}{
+		{HighlightANSI, "\x1b[35mvar\x1b[0m s = \x1b[32m\"<b>\"\x1b[0m; \x1b[90m// a//b\x1b[0m\n" +
+			"\x1b[35mprint\x1b[0m s + \x1b[36m1\x1b[0m @\n"},
+		{HighlightHTML, `<pre class="lox"><span class="lox-keyword">var</span> s = ` +
+			`<span class="lox-string">&#34;&lt;b&gt;&#34;</span>; <span class="lox-comment">// a//b</span>` + "\n" +
+			`<span class="lox-keyword">print</span> s + <span class="lox-number">1</span> @` + "\n</pre>\n"},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		if err := Highlight(&out, source, tt.format); err != nil {
+			t.Fatal(err)
+		}
+		if out.String() != tt.expected {
+			t.Errorf("Highlight result\n%q\nexpected\n%q", out.String(), tt.expected)
+		}
+	}
+}
@@ -1,38 +1,133 @@
 package golox
 
 import (
+	"context"
 	"fmt"
-	"math"
+	"io"
+	"math/rand"
+	"os"
 	"reflect"
+	"regexp"
+	"strconv"
+	"time"
 )
 
 type Interpreter struct {
 	lox         *Lox
 	globals     *Environment
 	environment *Environment
+	fileRoot    string
+	envLookup   func(key string) (string, bool)
+	timeSource  TimeSource
+	started     time.Time
+	random      *rand.Rand
+	patterns    map[string]*regexp.Regexp
+	// printing - lists and maps being stringified, seeing one again means
+	// it contains itself
+	printing map[any]bool
+	// ctx of the running script, checked by loops and calls
+	ctx      context.Context
+	out      io.Writer
+	errOut   io.Writer
+	coverage *Coverage
+	profiler *Profiler
+	// typeCheck - run the TypeChecker before executing, see WithTypeCheck
+	typeCheck bool
+	// diagnostics - replaces the text on errOut when set
+	diagnostics *Diagnostics
 }
 
-func NewInterpreter(lox *Lox) *Interpreter {
+// Option - configures an Interpreter at construction time
+type Option func(i *Interpreter)
 
-	globals := NewEnvironment()
-	globals.define("clock", NewClock())
+// WithOutput - where print writes, os.Stdout by default
+func WithOutput(w io.Writer) Option {
+	return func(i *Interpreter) {
+		i.out = w
+	}
+}
+
+// WithErrorOutput - where syntax and runtime errors are reported,
+// os.Stdout by default
+func WithErrorOutput(w io.Writer) Option {
+	return func(i *Interpreter) {
+		i.errOut = w
+	}
+}
+
+func NewInterpreter(lox *Lox, options ...Option) *Interpreter {
 
-	return &Interpreter{
+	globals := NewEnvironment()
+	globals.define("format", NewNativeFunction("format", 2, nativeFormat))
+	defineCollectionNatives(globals)
+	defineFileNatives(globals)
+	defineJSONNatives(globals)
+	defineProcessNatives(globals)
+	defineTimeNatives(globals)
+	defineRandomNatives(globals)
+	defineRegexNatives(globals)
+	defineAssertNatives(globals)
+
+	interpreter := &Interpreter{
 		lox:         lox,
 		globals:     globals,
 		environment: globals,
-	}
+		timeSource:  systemTime{},
+		started:     time.Now(),
+		random:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		patterns:    make(map[string]*regexp.Regexp),
+		printing:    make(map[any]bool),
+		ctx:         context.Background(),
+		out:         os.Stdout,
+		errOut:      os.Stdout,
+	}
+	for _, option := range options {
+		option(interpreter)
+	}
+	return interpreter
 }
 
-func (i *Interpreter) interpret(statements []Stmt) {
+// interpret - run statements until ctx is done; runtime errors are
+// reported through lox and all errors are returned to the caller
+func (i *Interpreter) interpret(ctx context.Context, statements []Stmt) error {
+	previous := i.ctx
+	i.ctx = ctx
+	defer func() { i.ctx = previous }()
+
 	for _, statement := range statements {
 		err := i.execute(statement)
 		if err != nil {
-			// TODO - better type casting
-			i.lox.RuntimeError(err.(RuntimeError))
-			return
+			switch e := err.(type) {
+			case ExitError:
+				i.lox.Exit(e.Code)
+			case CancelError:
+				// the host stopped the script, it knows why
+			case ReturnValue:
+				// only from an AST the parser did not check
+				runtimeError := NewRuntimeError(*e.keyword, e.Error())
+				i.lox.RuntimeError(runtimeError)
+				err = runtimeError
+			case RuntimeError:
+				i.lox.RuntimeError(e)
+			default:
+				runtimeError := NewRuntimeError(Token{}, err.Error())
+				i.lox.RuntimeError(runtimeError)
+				err = runtimeError
+			}
+			return err
 		}
 	}
+	return nil
+}
+
+// checkCancelled - CancelError once the context of the script is done
+func (i *Interpreter) checkCancelled() error {
+	select {
+	case <-i.ctx.Done():
+		return NewCancelError(i.ctx.Err())
+	default:
+		return nil
+	}
 }
 
 /* Intepreter implements on both ExprVisitor and StmtVisitor interfaces
@@ -70,9 +165,13 @@ func (i *Interpreter) visitIfStmt(stmt *If) (any, error) {
 		return nil, err
 	}
 	if i.isTruthy(cond) {
+		i.hitBranch(stmt, 0)
 		err = i.execute(stmt.thenBranch)
-	} else if stmt.elseBranch != nil {
-		err = i.execute(stmt.elseBranch)
+	} else {
+		i.hitBranch(stmt, 1)
+		if stmt.elseBranch != nil {
+			err = i.execute(stmt.elseBranch)
+		}
 	}
 	if err != nil {
 		return nil, err
@@ -85,7 +184,7 @@ func (i *Interpreter) visitPrintStmt(stmt *Print) (any, error) {
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println(i.stringify(value))
+	fmt.Fprintln(i.out, i.stringify(value))
 	return nil, nil
 }
 
@@ -99,7 +198,7 @@ func (i *Interpreter) visitReturnStmt(stmt *Return) (any, error) {
 		}
 	}
 	// act as throwing an exception with custom return value
-	return nil, NewReturnValue(value)
+	return nil, NewReturnValue(stmt.keyword, value)
 }
 
 func (i *Interpreter) visitVarStmt(stmt *Var) (any, error) {
@@ -122,6 +221,9 @@ func (i *Interpreter) visitWhileStmt(stmt *While) (any, error) {
 	}
 
 	for i.isTruthy(cond) {
+		if err := i.checkCancelled(); err != nil {
+			return nil, err
+		}
 		err := i.execute(stmt.body)
 		if err != nil {
 			return nil, err
@@ -146,6 +248,87 @@ func (i *Interpreter) visitAssignExpr(expr *Assign) (any, error) {
 	return value, nil
 }
 
+// compoundOperators - the binary operator behind each compound assignment
+var compoundOperators = map[TokenType]TokenType{
+	TkPlusEqual:  TkPlus,
+	TkMinusEqual: TkMinus,
+	TkStarEqual:  TkStar,
+	TkSlashEqual: TkSlash,
+	TkPlusPlus:   TkPlus,
+	TkMinusMinus: TkMinus,
+}
+
+func (i *Interpreter) visitCompoundAssignExpr(expr *CompoundAssign) (any, error) {
+	operator := *expr.operator
+	operator.kind = compoundOperators[expr.operator.kind]
+	_, value, err := i.update(expr.target, func(current any) (any, error) {
+		right, err := i.evaluate(expr.value)
+		if err != nil {
+			return nil, err
+		}
+		return i.binary(&operator, current, right)
+	})
+	return value, err
+}
+
+func (i *Interpreter) visitIncrementExpr(expr *Increment) (any, error) {
+	operator := *expr.operator
+	operator.kind = compoundOperators[expr.operator.kind]
+	old, value, err := i.update(expr.target, func(current any) (any, error) {
+		if err := i.checkNumberOperand(expr.operator, current); err != nil {
+			return nil, err
+		}
+		return i.binary(&operator, current, int64(1))
+	})
+	if err != nil {
+		return nil, err
+	}
+	if expr.prefix {
+		return value, nil
+	}
+	return old, nil
+}
+
+// update - read-modify-write an assignable target, resolving the target
+// only once; returns both the old and the new value
+func (i *Interpreter) update(target Expr, modify func(current any) (any, error)) (any, any, error) {
+	switch t := target.(type) {
+	case *Variable:
+		current, err := i.environment.get(t.name)
+		if err != nil {
+			return nil, nil, err
+		}
+		value, err := modify(current)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := i.environment.assign(t.name, value); err != nil {
+			return nil, nil, err
+		}
+		return current, value, nil
+	case *Get:
+		object, err := i.evaluate(t.object)
+		if err != nil {
+			return nil, nil, err
+		}
+		current, err := i.getProperty(object, t.name)
+		if err != nil {
+			return nil, nil, err
+		}
+		value, err := modify(current)
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := i.setProperty(object, t.name, value); err != nil {
+			return nil, nil, err
+		}
+		return current, value, nil
+	}
+
+	// unreachable, the parser only accepts assignable targets
+	return nil, nil, nil
+}
+
 func (i *Interpreter) visitLiteralExpr(expr *Literal) (any, error) {
 	return expr.value, nil
 }
@@ -155,22 +338,88 @@ func (i *Interpreter) visitLogicalExpr(expr *Logical) (any, error) {
 	if err != nil {
 		return nil, err
 	}
-	if expr.operator.kind == TkOr {
+	if expr.operator.kind == TkQuestionQuestion {
+		// short circuit for ??, only nil falls through
+		if left != nil {
+			i.hitBranch(expr, 0)
+			return left, nil
+		}
+	} else if expr.operator.kind == TkOr {
 		// short circuit for OR
 		if i.isTruthy(left) {
+			i.hitBranch(expr, 0)
 			return left, nil
 		}
 	} else {
 		// short circuit for AND
 		if !i.isTruthy(left) {
+			i.hitBranch(expr, 0)
 			return left, nil
 		}
 	}
 
+	i.hitBranch(expr, 1)
 	return i.evaluate(expr.right)
 
 }
 
+func (i *Interpreter) visitConditionalExpr(expr *Conditional) (any, error) {
+	cond, err := i.evaluate(expr.condition)
+	if err != nil {
+		return nil, err
+	}
+	if i.isTruthy(cond) {
+		return i.evaluate(expr.thenBranch)
+	}
+	return i.evaluate(expr.elseBranch)
+}
+
+func (i *Interpreter) visitGetExpr(expr *Get) (any, error) {
+	object, err := i.evaluate(expr.object)
+	if err != nil {
+		return nil, err
+	}
+	return i.getProperty(object, expr.name)
+}
+
+func (i *Interpreter) visitSetExpr(expr *Set) (any, error) {
+	object, err := i.evaluate(expr.object)
+	if err != nil {
+		return nil, err
+	}
+	value, err := i.evaluate(expr.value)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.setProperty(object, expr.name, value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+func (i *Interpreter) getProperty(object any, name *Token) (any, error) {
+	holder, ok := object.(propertyHolder)
+	if !ok {
+		return nil, NewRuntimeError(*name, "Only objects have properties.")
+	}
+	value, err := holder.getProperty(name.lexeme)
+	if err != nil {
+		return nil, NewRuntimeError(*name, err.Error())
+	}
+	return value, nil
+}
+
+func (i *Interpreter) setProperty(object any, name *Token, value any) error {
+	holder, ok := object.(propertyHolder)
+	if !ok {
+		return NewRuntimeError(*name, "Only objects have fields.")
+	}
+	if err := holder.setProperty(name.lexeme, value); err != nil {
+		return NewRuntimeError(*name, err.Error())
+	}
+	return nil
+}
+
 func (i *Interpreter) visitGroupingExpr(expr *Grouping) (any, error) {
 	return i.evaluate(expr.expression)
 }
@@ -188,7 +437,13 @@ func (i *Interpreter) visitUnaryExpr(expr *Unary) (any, error) {
 		if err != nil {
 			return nil, err
 		}
-		return -(right.(float64)), nil
+		return negate(right), nil
+	case TkTilde:
+		value, err := i.checkIntegerOperand(expr.operator, right)
+		if err != nil {
+			return nil, err
+		}
+		return ^value, nil
 	}
 
 	// unreachable
@@ -208,67 +463,76 @@ func (i *Interpreter) visitBinaryExpr(expr *Binary) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	return i.binary(expr.operator, left, right)
+}
 
-	switch expr.operator.kind {
+// binary - apply a binary operator to already evaluated operands
+func (i *Interpreter) binary(operator *Token, left any, right any) (any, error) {
+	switch operator.kind {
 
 	case TkGreater:
-		if err := i.checkNumberOperands(expr.operator, left, right); err != nil {
+		if err := i.checkNumberOperands(operator, left, right); err != nil {
 			return nil, err
 		}
-		return left.(float64) > right.(float64), nil
+		return compareNumbers(left, right) > 0, nil
 	case TkGreaterEqual:
-		if err := i.checkNumberOperands(expr.operator, left, right); err != nil {
+		if err := i.checkNumberOperands(operator, left, right); err != nil {
 			return nil, err
 		}
-		return left.(float64) >= right.(float64), nil
+		return compareNumbers(left, right) >= 0, nil
 	case TkLess:
-		if err := i.checkNumberOperands(expr.operator, left, right); err != nil {
+		if err := i.checkNumberOperands(operator, left, right); err != nil {
 			return nil, err
 		}
-		return left.(float64) < right.(float64), nil
+		return compareNumbers(left, right) < 0, nil
 	case TkLessEqual:
-		if err := i.checkNumberOperands(expr.operator, left, right); err != nil {
+		if err := i.checkNumberOperands(operator, left, right); err != nil {
 			return nil, err
 		}
-		return left.(float64) <= right.(float64), nil
+		return compareNumbers(left, right) <= 0, nil
 	// comparison case
 	case TkBangEqual:
 		return !i.isEqual(left, right), nil
 	case TkEqualEqual:
 		return i.isEqual(left, right), nil
 
-	case TkMinus:
-		if err := i.checkNumberOperands(expr.operator, left, right); err != nil {
+	case TkMinus, TkStar, TkStarStar:
+		if err := i.checkNumberOperands(operator, left, right); err != nil {
 			return nil, err
 		}
-		return left.(float64) - right.(float64), nil
+		return arithmetic(operator.kind, left, right), nil
 	case TkPlus:
 
 		// add
-		if reflect.TypeOf(left).Kind() == reflect.Float64 &&
-			reflect.TypeOf(right).Kind() == reflect.Float64 {
-			return left.(float64) + right.(float64), nil
+		if isNumber(left) && isNumber(right) {
+			return arithmetic(operator.kind, left, right), nil
 		}
 
 		// concatinate
-		if reflect.TypeOf(left).Kind() == reflect.String &&
-			reflect.TypeOf(right).Kind() == reflect.String {
-			return left.(string) + right.(string), nil
+		if l, ok := left.(string); ok {
+			if r, ok := right.(string); ok {
+				return l + r, nil
+			}
 		}
 
 		// otherwise, error
-		return nil, NewRuntimeError(*expr.operator, "Operands must be two numbers or two strings.")
+		return nil, NewRuntimeError(*operator, "Operands must be two numbers or two strings.")
 
 	case TkSlash:
-		if err := i.checkNumberOperands(expr.operator, left, right); err != nil {
+		if err := i.checkDivisor(operator, left, right); err != nil {
 			return nil, err
 		}
-		return left.(float64) / right.(float64), nil
-	case TkStar:
-		if err := i.checkNumberOperands(expr.operator, left, right); err != nil {
+		// true division, use ~/ for integer division
+		return toFloat(left) / toFloat(right), nil
+	case TkPercent, TkTildeSlash:
+		if err := i.checkDivisor(operator, left, right); err != nil {
 			return nil, err
 		}
-		return left.(float64) * right.(float64), nil
+		return arithmetic(operator.kind, left, right), nil
+
+	// bitwise case
+	case TkAmpersand, TkPipe, TkCaret, TkLessLess, TkGreaterGreater:
+		return i.bitwise(operator, left, right)
 	}
 
 	// unreachable
@@ -298,39 +562,134 @@ func (i *Interpreter) visitCallExpr(expr *Call) (any, error) {
 		)
 	}
 
+	if err := i.checkCancelled(); err != nil {
+		return nil, err
+	}
+
 	if len(arguments) != function.arity() {
-		return nil, NewRuntimeError(
-			*expr.paren,
-			fmt.Sprintf("Expected %d arguments but got %d.",
-				function.arity(),
-				len(arguments),
-			),
-		)
+		if f, ok := function.(optionalArity); ok {
+			if len(arguments) < f.arity() || len(arguments) > f.arity()+f.optional() {
+				return nil, NewRuntimeError(
+					*expr.paren,
+					fmt.Sprintf("Expected %d to %d arguments but got %d.",
+						f.arity(),
+						f.arity()+f.optional(),
+						len(arguments),
+					),
+				)
+			}
+		} else {
+			return nil, NewRuntimeError(
+				*expr.paren,
+				fmt.Sprintf("Expected %d arguments but got %d.",
+					function.arity(),
+					len(arguments),
+				),
+			)
+		}
 	}
 
-	return function.call(i, arguments)
+	if i.profiler != nil {
+		i.profiler.enterCall(function, expr.paren.line)
+	}
+	value, err := function.call(i, arguments)
+	if i.profiler != nil {
+		i.profiler.leaveCall()
+	}
+	if err != nil {
+		switch err.(type) {
+		case RuntimeError, ExitError, CancelError:
+			return nil, err
+		}
+		// natives report plain errors, locate them at the call site
+		return nil, NewRuntimeError(*expr.paren, err.Error())
+	}
+	return value, nil
 }
 
 func (i *Interpreter) checkNumberOperand(operator *Token, operand any) error {
-	if reflect.TypeOf(operand).Kind() == reflect.Float64 {
+	if isNumber(operand) {
 		return nil
 	}
 	return NewRuntimeError(*operator, "Operand must be a number")
 }
 
 func (i *Interpreter) checkNumberOperands(operator *Token, left any, right any) error {
-	if reflect.TypeOf(left).Kind() == reflect.Float64 &&
-		reflect.TypeOf(right).Kind() == reflect.Float64 {
+	if isNumber(left) && isNumber(right) {
 		return nil
 	}
 	return NewRuntimeError(*operator, "Operands must be numbers")
 }
 
+// checkDivisor - number operands with a non-zero right-hand side
+// division by zero is a runtime error rather than Inf or NaN
+func (i *Interpreter) checkDivisor(operator *Token, left any, right any) error {
+	if err := i.checkNumberOperands(operator, left, right); err != nil {
+		return err
+	}
+	if toFloat(right) == 0 {
+		return NewRuntimeError(*operator, "Division by zero.")
+	}
+	return nil
+}
+
+func (i *Interpreter) checkIntegerOperand(operator *Token, operand any) (int64, error) {
+	value, ok := operand.(int64)
+	if !ok {
+		return 0, NewRuntimeError(*operator, "Operand must be an integer.")
+	}
+	return value, nil
+}
+
+func (i *Interpreter) bitwise(operator *Token, left any, right any) (any, error) {
+	a, err := i.checkIntegerOperand(operator, left)
+	if err != nil {
+		return nil, NewRuntimeError(*operator, "Operands must be integers.")
+	}
+	b, err := i.checkIntegerOperand(operator, right)
+	if err != nil {
+		return nil, NewRuntimeError(*operator, "Operands must be integers.")
+	}
+
+	switch operator.kind {
+	case TkAmpersand:
+		return a & b, nil
+	case TkPipe:
+		return a | b, nil
+	case TkCaret:
+		return a ^ b, nil
+	case TkLessLess, TkGreaterGreater:
+		if b < 0 {
+			return nil, NewRuntimeError(*operator, "Shift count must not be negative.")
+		}
+		if b >= 64 {
+			return nil, NewRuntimeError(*operator, "Shift count must be less than 64.")
+		}
+		if operator.kind == TkLessLess {
+			// unlike + - * there is no float to promote to, bits would be lost
+			if (a<<b)>>b != a {
+				return nil, NewRuntimeError(*operator, "Shift overflows a 64-bit integer.")
+			}
+			return a << b, nil
+		}
+		return a >> b, nil
+	}
+
+	// unreachable
+	return nil, nil
+}
+
 func (i *Interpreter) evaluate(expr Expr) (any, error) {
 	return expr.Accept(i)
 }
 
 func (i *Interpreter) execute(stmt Stmt) error {
+	if i.coverage != nil {
+		i.coverage.hitStatement(stmt)
+	}
+	if i.profiler != nil {
+		defer i.profiler.leaveStatement(i.profiler.enterStatement(stmt))
+	}
 	_, err := stmt.Accept(i)
 	if err != nil {
 		return err
@@ -338,6 +697,13 @@ func (i *Interpreter) execute(stmt Stmt) error {
 	return nil
 }
 
+// hitBranch - record which way a branch point went when collecting coverage
+func (i *Interpreter) hitBranch(node any, branch int) {
+	if i.coverage != nil {
+		i.coverage.hitBranch(node, branch)
+	}
+}
+
 func (i *Interpreter) visitBlockStmt(stmt *Block) (any, error) {
 	err := i.executeBlock(stmt.statements,
 		NewEnvironmentWithEnclosing(i.environment))
@@ -381,6 +747,14 @@ func (i *Interpreter) isEqual(a any, b any) bool {
 	if a == nil {
 		return false
 	}
+	if isNumber(a) && isNumber(b) {
+		return numbersEqual(a, b)
+	}
+	if t, ok := a.(LoxTime); ok {
+		if u, ok := b.(LoxTime); ok {
+			return t.time.Equal(u.time)
+		}
+	}
 	// TODO - ensure the golang comparison machanism
 	return a == b
 }
@@ -389,14 +763,17 @@ func (i *Interpreter) stringify(a any) string {
 	if a == nil {
 		return "nil"
 	}
-	if reflect.TypeOf(a).Kind() == reflect.Float64 {
-
-		value := a.(float64)
-		if math.Mod(value, 1.0) == 0 {
-			return fmt.Sprintf("%.0f", value)
-		}
-		return fmt.Sprintf("%.2f", value)
-
+	if value, ok := a.(int64); ok {
+		return strconv.FormatInt(value, 10)
+	}
+	if value, ok := a.(float64); ok {
+		return formatFloat(value)
+	}
+	if list, ok := a.(*LoxList); ok {
+		return list.stringify(i)
+	}
+	if m, ok := a.(*LoxMap); ok {
+		return m.stringify(i)
 	}
 
 	return fmt.Sprintf("%v", a)
@@ -0,0 +1,31 @@
+package golox
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestInterpreter_ShiftErrors(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"print 1 << 70;", "Shift count must be less than 64."},
+		{"print 1 >> 64;", "Shift count must be less than 64."},
+		{"print 1 << -1;", "Shift count must not be negative."},
+		{"print 1 << 63;", "Shift overflows a 64-bit integer."},
+		{"print 3 << 62;", "Shift overflows a 64-bit integer."},
+		{"print -2 << 63;", "Shift overflows a 64-bit integer."},
+		{"print 1.5 << 1;", "Operands must be integers."},
+	}
+	for _, tt := range tests {
+		var out bytes.Buffer
+		err := NewLox(WithOutput(&out), WithErrorOutput(&out)).Run(tt.source)
+		if _, ok := err.(RuntimeError); !ok {
+			t.Errorf("%s error %v, expected a RuntimeError", tt.source, err)
+		}
+		if expected := tt.expected + "\n[line 1]\n"; out.String() != expected {
+			t.Errorf("%s output %q, expected %q", tt.source, out.String(), expected)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+package golox
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+func defineJSONNatives(globals *Environment) {
+	globals.define("jsonParse", NewNativeFunction("jsonParse", 1, nativeJSONParse))
+	globals.define("jsonStringify", NewNativeFunctionWithOptional("jsonStringify", 1, 1, nativeJSONStringify))
+}
+
+// jsonParse(string) - objects become maps, arrays lists, integral numbers
+// int64 and the other numbers float64
+func nativeJSONParse(i *Interpreter, arguments []any) (any, error) {
+	text, ok := arguments[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("jsonParse expects a string.")
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	value, err := decodeJSON(decoder)
+	if err != nil {
+		return nil, jsonSyntaxError(text, decoder.InputOffset(), err)
+	}
+	end := decoder.InputOffset()
+	if _, err := decoder.Token(); err != io.EOF {
+		if err == nil {
+			err = errors.New("unexpected data after top-level value")
+			end += int64(len(text[end:]) - len(strings.TrimLeft(text[end:], " \t\r\n")))
+		}
+		return nil, jsonSyntaxError(text, end, err)
+	}
+	return value, nil
+}
+
+// decodeJSON - build Lox values from the token stream, unlike
+// json.Unmarshal this keeps the key order of objects
+func decodeJSON(decoder *json.Decoder) (any, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := token.(type) {
+	case json.Delim:
+		if t == '[' {
+			elements := []any{}
+			for decoder.More() {
+				element, err := decodeJSON(decoder)
+				if err != nil {
+					return nil, err
+				}
+				elements = append(elements, element)
+			}
+			// the closing ]
+			if _, err := decoder.Token(); err != nil {
+				return nil, err
+			}
+			return NewLoxList(elements), nil
+		}
+
+		object := NewLoxMap()
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeJSON(decoder)
+			if err != nil {
+				return nil, err
+			}
+			object.set(key.(string), value)
+		}
+		// the closing }
+		if _, err := decoder.Token(); err != nil {
+			return nil, err
+		}
+		return object, nil
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return n, nil
+		}
+		f, err := t.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("number %s out of range", t)
+		}
+		return f, nil
+	}
+
+	// string, bool or nil
+	return token, nil
+}
+
+// jsonSyntaxError - locate the error by line and column in the input
+func jsonSyntaxError(text string, offset int64, err error) error {
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		// the offset counts the character the error is about
+		offset = syntaxErr.Offset - 1
+		if syntaxErr.Error() == "unexpected end of JSON input" {
+			err = io.ErrUnexpectedEOF
+		}
+	}
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		offset = int64(len(text))
+		err = errors.New("unexpected end of input")
+	}
+	if offset > int64(len(text)) {
+		offset = int64(len(text))
+	}
+
+	line := 1 + strings.Count(text[:offset], "\n")
+	column := offset - int64(strings.LastIndex(text[:offset], "\n"))
+	return fmt.Errorf("Invalid JSON at line %d, column %d: %s.", line, column, err)
+}
+
+// jsonStringify(value, indent?) - indent is a number of spaces or a string
+func nativeJSONStringify(i *Interpreter, arguments []any) (any, error) {
+	indent := ""
+	switch v := arguments[1].(type) {
+	case nil:
+	case int64:
+		if v < 0 || v > 10 {
+			return nil, fmt.Errorf("JSON indent must be between 0 and 10 spaces.")
+		}
+		indent = strings.Repeat(" ", int(v))
+	case string:
+		indent = v
+	default:
+		return nil, fmt.Errorf("JSON indent must be a number or a string.")
+	}
+
+	encoder := &jsonEncoder{
+		interpreter: i,
+		indent:      indent,
+		visiting:    make(map[any]bool),
+	}
+	if err := encoder.encode(arguments[0], 0); err != nil {
+		return nil, err
+	}
+	return encoder.sb.String(), nil
+}
+
+type jsonEncoder struct {
+	interpreter *Interpreter
+	indent      string
+	sb          strings.Builder
+	// containers on the current path, seeing one again means a cycle
+	visiting map[any]bool
+}
+
+func (e *jsonEncoder) encode(value any, depth int) error {
+	switch v := value.(type) {
+	case nil:
+		e.sb.WriteString("null")
+	case bool:
+		e.sb.WriteString(strconv.FormatBool(v))
+	case int64:
+		e.sb.WriteString(strconv.FormatInt(v, 10))
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("Cannot encode %s as JSON.", formatFloat(v))
+		}
+		e.sb.WriteString(formatFloat(v))
+	case string:
+		e.encodeString(v)
+	case *LoxList:
+		if e.visiting[v] {
+			return fmt.Errorf("Cannot encode cyclic structure as JSON.")
+		}
+		e.visiting[v] = true
+		defer delete(e.visiting, v)
+
+		e.sb.WriteString("[")
+		for n, element := range v.elements {
+			if n > 0 {
+				e.sb.WriteString(",")
+			}
+			e.newline(depth + 1)
+			if err := e.encode(element, depth+1); err != nil {
+				return err
+			}
+		}
+		if len(v.elements) > 0 {
+			e.newline(depth)
+		}
+		e.sb.WriteString("]")
+	case *LoxMap:
+		if e.visiting[v] {
+			return fmt.Errorf("Cannot encode cyclic structure as JSON.")
+		}
+		e.visiting[v] = true
+		defer delete(e.visiting, v)
+
+		e.sb.WriteString("{")
+		for n, key := range v.keys {
+			if n > 0 {
+				e.sb.WriteString(",")
+			}
+			e.newline(depth + 1)
+			e.encodeString(key)
+			e.sb.WriteString(":")
+			if e.indent != "" {
+				e.sb.WriteString(" ")
+			}
+			if err := e.encode(v.values[key], depth+1); err != nil {
+				return err
+			}
+		}
+		if len(v.keys) > 0 {
+			e.newline(depth)
+		}
+		e.sb.WriteString("}")
+	default:
+		return fmt.Errorf("Cannot encode %s as JSON.", e.interpreter.stringify(v))
+	}
+	return nil
+}
+
+func (e *jsonEncoder) encodeString(s string) {
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	// encoding a string never fails
+	encoder.Encode(s)
+	e.sb.WriteString(strings.TrimSuffix(buf.String(), "\n"))
+}
+
+func (e *jsonEncoder) newline(depth int) {
+	if e.indent == "" {
+		return
+	}
+	e.sb.WriteString("\n")
+	e.sb.WriteString(strings.Repeat(e.indent, depth))
+}
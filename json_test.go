@@ -0,0 +1,109 @@
+package golox
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJSONParse_Numbers(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected any
+	}{
+		{"1", int64(1)},
+		{"-42", int64(-42)},
+		{"1.0", 1.0},
+		{"1.5", 1.5},
+		{"1e3", 1000.0},
+		{"9223372036854775808", 9223372036854775808.0},
+	}
+	for _, tt := range tests {
+		value, err := nativeJSONParse(nil, []any{tt.text})
+		if err != nil {
+			t.Errorf("jsonParse(%q) error %v", tt.text, err)
+			continue
+		}
+		if value != tt.expected {
+			t.Errorf("jsonParse(%q) result %T %v, expected %T %v", tt.text, value, value, tt.expected, tt.expected)
+		}
+	}
+}
+
+func TestJSONParse_KeyOrder(t *testing.T) {
+	value, err := nativeJSONParse(nil, []any{`{"b": 1, "a": [true, null, "x"], "c": {"z": 1, "y": 2}}`})
+	if err != nil {
+		t.Fatal(err)
+	}
+	object := value.(*LoxMap)
+	if !reflect.DeepEqual(object.keyList(), []any{"b", "a", "c"}) {
+		t.Errorf("keys %v, expected [b a c]", object.keyList())
+	}
+	nested, _ := object.get("c")
+	if keys := nested.(*LoxMap).keyList(); !reflect.DeepEqual(keys, []any{"z", "y"}) {
+		t.Errorf("nested keys %v, expected [z y]", keys)
+	}
+	list, _ := object.get("a")
+	if elements := list.(*LoxList).elements; !reflect.DeepEqual(elements, []any{true, nil, "x"}) {
+		t.Errorf("list %v, expected [true <nil> x]", elements)
+	}
+
+	interpreter := NewInterpreter(NewLox())
+	text, err := nativeJSONStringify(interpreter, []any{value, nil})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := `{"b":1,"a":[true,null,"x"],"c":{"z":1,"y":2}}`; text != expected {
+		t.Errorf("jsonStringify result %s, expected %s", text, expected)
+	}
+}
+
+func TestJSONParse_Errors(t *testing.T) {
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"", "Invalid JSON at line 1, column 1: unexpected end of input."},
+		{"[1, 2", "Invalid JSON at line 1, column 6: unexpected end of input."},
+		{"nul", "Invalid JSON at line 1, column 4: unexpected end of input."},
+		{"{\n  \"a\": 1,\n  \"b\" 2\n}", "Invalid JSON at line 3, column 7: invalid character '2' after object key."},
+		{"[1, x]", "Invalid JSON at line 1, column 5: invalid character 'x' looking for beginning of value."},
+		{"{1: 2}", "Invalid JSON at line 1, column 2: object member name must be a string."},
+		{"1 2", "Invalid JSON at line 1, column 3: unexpected data after top-level value."},
+	}
+	for _, tt := range tests {
+		_, err := nativeJSONParse(nil, []any{tt.text})
+		if err == nil || !strings.HasPrefix(err.Error(), tt.expected) {
+			t.Errorf("jsonParse(%q) error %v, expected %q", tt.text, err, tt.expected)
+		}
+	}
+
+	if _, err := nativeJSONParse(nil, []any{1.0}); err == nil {
+		t.Errorf("jsonParse(1) allowed, expected a string")
+	}
+}
+
+func TestJSONStringify_Cycles(t *testing.T) {
+	interpreter := NewInterpreter(NewLox())
+
+	list := NewLoxList([]any{1.0})
+	list.elements = append(list.elements, list)
+	object := NewLoxMap()
+	object.set("self", object)
+	nested := NewLoxMap()
+	nested.set("list", NewLoxList([]any{nested}))
+
+	for _, value := range []any{list, object, nested} {
+		_, err := nativeJSONStringify(interpreter, []any{value, nil})
+		if err == nil || err.Error() != "Cannot encode cyclic structure as JSON." {
+			t.Errorf("jsonStringify of a cycle error %v, expected the cyclic structure error", err)
+		}
+	}
+
+	// the same list twice is shared, not a cycle
+	shared := NewLoxList([]any{int64(1)})
+	text, err := nativeJSONStringify(interpreter, []any{NewLoxList([]any{shared, shared}), nil})
+	if err != nil || text != "[[1],[1]]" {
+		t.Errorf("jsonStringify of a shared list result %v %v, expected [[1],[1]]", text, err)
+	}
+}
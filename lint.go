@@ -0,0 +1,369 @@
+package golox
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Lint rules, the IDs are what suppression comments name
+const (
+	RuleUnusedVariable    = "unused-variable"
+	RuleShadowedParameter = "shadowed-parameter"
+	RuleUnreachableCode   = "unreachable-code"
+	RuleUndeclaredGlobal  = "undeclared-global"
+	RuleWrongArity        = "wrong-arity"
+)
+
+// LintWarning - a likely mistake found by Lint
+type LintWarning struct {
+	Line    int
+	Rule    string
+	Message string
+}
+
+func (w LintWarning) String() string {
+	return fmt.Sprintf("line %d: %s (%s)", w.Line, w.Message, w.Rule)
+}
+
+// Diagnostic - the warning as found in file
+func (w LintWarning) Diagnostic(file string) Diagnostic {
+	return Diagnostic{
+		Severity: SeverityWarning,
+		Code:     w.Rule,
+		File:     file,
+		Line:     w.Line,
+		Message:  w.Message,
+	}
+}
+
+// lintIgnorePattern - "// lint:ignore" silences every rule on its line,
+// "// lint:ignore rule, rule" only the listed ones; a comment alone on
+// its line applies to the next line
+var lintIgnorePattern = regexp.MustCompile(`//\s*lint:ignore\b([\w\s,-]*)`)
+
+// Lint - check source for likely mistakes without running it; syntax
+// errors, and type errors under WithTypeCheck, are reported as usual and
+// ErrSyntax is returned, after a type error along with the warnings
+func Lint(source string, options ...Option) ([]LintWarning, error) {
+	lox := NewLox(options...)
+	program, err := lox.parse("", source)
+	if err != nil {
+		return nil, err
+	}
+	if lox.interpreter.typeCheck {
+		NewTypeChecker(lox).Check(program.statements)
+		if lox.hadError {
+			err = ErrSyntax
+		}
+	}
+
+	linter := &linter{
+		lines:    program.lines,
+		builtins: lox.interpreter.globals.values,
+		globals:  make(map[string]*Function),
+		declared: make(map[string]bool),
+	}
+	linter.lint(program.statements)
+
+	ignored := lintIgnored(source)
+	var warnings []LintWarning
+	for _, warning := range linter.warnings {
+		rules := ignored[warning.Line]
+		if rules != nil && (rules[""] || rules[warning.Rule]) {
+			continue
+		}
+		warnings = append(warnings, warning)
+	}
+	sort.SliceStable(warnings, func(a, b int) bool {
+		return warnings[a].Line < warnings[b].Line
+	})
+	return warnings, err
+}
+
+// lintIgnored - rules silenced per line, "" stands for all of them
+func lintIgnored(source string) map[int]map[string]bool {
+	ignored := make(map[int]map[string]bool)
+	for n, text := range strings.Split(source, "\n") {
+		m := lintIgnorePattern.FindStringSubmatchIndex(text)
+		if m == nil {
+			continue
+		}
+		rules := make(map[string]bool)
+		for _, rule := range strings.FieldsFunc(text[m[2]:m[3]], func(r rune) bool {
+			return r == ',' || r == ' ' || r == '\t'
+		}) {
+			rules[rule] = true
+		}
+		if len(rules) == 0 {
+			rules[""] = true
+		}
+
+		line := n + 1
+		ignored[line] = rules
+		if strings.TrimSpace(text[:m[0]]) == "" {
+			ignored[line+1] = rules
+		}
+	}
+	return ignored
+}
+
+// lintVariable - a local variable, parameter or function in a scope
+type lintVariable struct {
+	name     *Token
+	used     bool
+	param    bool
+	function *Function
+}
+
+type lintScope struct {
+	variables map[string]*lintVariable
+	// function - the function this scope is the body of, nil for blocks
+	function *Function
+}
+
+type linter struct {
+	lines    map[Stmt]int
+	builtins map[string]any
+	// globals - top-level functions declared exactly once
+	globals map[string]*Function
+	// declared - every top-level name, globals are late bound so a
+	// function may assign one declared further down
+	declared map[string]bool
+	scopes   []*lintScope
+	warnings []LintWarning
+}
+
+func (l *linter) warn(line int, rule string, format string, args ...any) {
+	l.warnings = append(l.warnings, LintWarning{line, rule, fmt.Sprintf(format, args...)})
+}
+
+func (l *linter) lint(statements []Stmt) {
+	redeclared := make(map[string]bool)
+	for _, stmt := range statements {
+		var name string
+		switch s := stmt.(type) {
+		case *Var:
+			name = s.name.lexeme
+		case *Function:
+			name = s.name.lexeme
+			l.globals[name] = s
+		default:
+			continue
+		}
+		if l.declared[name] {
+			redeclared[name] = true
+		}
+		l.declared[name] = true
+	}
+	for name := range redeclared {
+		delete(l.globals, name)
+	}
+
+	l.statements(statements)
+}
+
+// statements - a statement list, anything after a return is unreachable
+func (l *linter) statements(statements []Stmt) {
+	for n, stmt := range statements {
+		l.stmt(stmt)
+		if _, ok := stmt.(*Return); ok && n+1 < len(statements) {
+			l.warn(l.lines[statements[n+1]], RuleUnreachableCode, "Unreachable code after return.")
+			for _, rest := range statements[n+1:] {
+				l.stmt(rest)
+			}
+			return
+		}
+	}
+}
+
+func (l *linter) beginScope(function *Function) {
+	l.scopes = append(l.scopes, &lintScope{
+		variables: make(map[string]*lintVariable),
+		function:  function,
+	})
+}
+
+func (l *linter) endScope() {
+	scope := l.scopes[len(l.scopes)-1]
+	l.scopes = l.scopes[:len(l.scopes)-1]
+
+	var unused []*lintVariable
+	for _, variable := range scope.variables {
+		if !variable.used && !variable.param && variable.function == nil &&
+			!strings.HasPrefix(variable.name.lexeme, "_") {
+			unused = append(unused, variable)
+		}
+	}
+	sort.Slice(unused, func(a, b int) bool {
+		return unused[a].name.line < unused[b].name.line
+	})
+	for _, variable := range unused {
+		l.warn(variable.name.line, RuleUnusedVariable,
+			"Local variable '%s' is never used.", variable.name.lexeme)
+	}
+}
+
+func (l *linter) declare(name *Token, variable *lintVariable) {
+	if len(l.scopes) == 0 {
+		return
+	}
+	l.scopes[len(l.scopes)-1].variables[name.lexeme] = variable
+}
+
+// lookup - the innermost local with this name, nil for globals
+func (l *linter) lookup(name string) *lintVariable {
+	for n := len(l.scopes) - 1; n >= 0; n-- {
+		if variable, ok := l.scopes[n].variables[name]; ok {
+			return variable
+		}
+	}
+	return nil
+}
+
+// shadowedParameter - the function whose parameter name would shadow,
+// searching out to the innermost function body
+func (l *linter) shadowedParameter(name string) *Function {
+	for n := len(l.scopes) - 1; n >= 0; n-- {
+		scope := l.scopes[n]
+		if variable, ok := scope.variables[name]; ok && variable.param {
+			return scope.function
+		}
+		if scope.function != nil {
+			return nil
+		}
+	}
+	return nil
+}
+
+func (l *linter) stmt(stmt Stmt) {
+	switch s := stmt.(type) {
+	case *Block:
+		l.beginScope(nil)
+		l.statements(s.statements)
+		l.endScope()
+	case *Expression:
+		l.expr(s.expression)
+	case *Function:
+		l.declare(s.name, &lintVariable{name: s.name, function: s})
+		l.beginScope(s)
+		for _, param := range s.params {
+			l.declare(param, &lintVariable{name: param, param: true})
+		}
+		l.statements(s.body)
+		l.endScope()
+	case *If:
+		l.expr(s.condition)
+		l.stmt(s.thenBranch)
+		if s.elseBranch != nil {
+			l.stmt(s.elseBranch)
+		}
+	case *Print:
+		l.expr(s.expression)
+	case *Return:
+		if s.value != nil {
+			l.expr(s.value)
+		}
+	case *Var:
+		if s.initializer != nil {
+			l.expr(s.initializer)
+		}
+		if function := l.shadowedParameter(s.name.lexeme); function != nil {
+			l.warn(s.name.line, RuleShadowedParameter,
+				"Variable '%s' shadows a parameter of '%s'.", s.name.lexeme, function.name.lexeme)
+		}
+		l.declare(s.name, &lintVariable{name: s.name})
+	case *While:
+		l.expr(s.condition)
+		l.stmt(s.body)
+	}
+}
+
+func (l *linter) expr(expr Expr) {
+	switch e := expr.(type) {
+	case *Assign:
+		l.expr(e.value)
+		l.assign(e.name, false)
+	case *Binary:
+		l.expr(e.left)
+		l.expr(e.right)
+	case *Call:
+		l.expr(e.callee)
+		for _, argument := range e.arguments {
+			l.expr(argument)
+		}
+		l.checkArity(e)
+	case *CompoundAssign:
+		l.target(e.target)
+		l.expr(e.value)
+	case *Conditional:
+		l.expr(e.condition)
+		l.expr(e.thenBranch)
+		l.expr(e.elseBranch)
+	case *Get:
+		l.expr(e.object)
+	case *Grouping:
+		l.expr(e.expression)
+	case *Increment:
+		l.target(e.target)
+	case *Logical:
+		l.expr(e.left)
+		l.expr(e.right)
+	case *Set:
+		l.expr(e.object)
+		l.expr(e.value)
+	case *Unary:
+		l.expr(e.right)
+	case *Variable:
+		if variable := l.lookup(e.name.lexeme); variable != nil {
+			variable.used = true
+		}
+	}
+}
+
+// target - of += or ++, which read the variable as well as assign it
+func (l *linter) target(target Expr) {
+	if variable, ok := target.(*Variable); ok {
+		l.assign(variable.name, true)
+		return
+	}
+	l.expr(target)
+}
+
+func (l *linter) assign(name *Token, reads bool) {
+	if variable := l.lookup(name.lexeme); variable != nil {
+		if reads {
+			variable.used = true
+		}
+		return
+	}
+	if _, ok := l.builtins[name.lexeme]; ok || l.declared[name.lexeme] {
+		return
+	}
+	l.warn(name.line, RuleUndeclaredGlobal,
+		"Assignment to undeclared variable '%s'.", name.lexeme)
+}
+
+func (l *linter) checkArity(call *Call) {
+	callee, ok := call.callee.(*Variable)
+	if !ok {
+		return
+	}
+	var function *Function
+	if variable := l.lookup(callee.name.lexeme); variable != nil {
+		function = variable.function
+	} else {
+		function = l.globals[callee.name.lexeme]
+	}
+	if function == nil || len(function.params) == len(call.arguments) {
+		return
+	}
+
+	plural := "s"
+	if len(function.params) == 1 {
+		plural = ""
+	}
+	l.warn(call.paren.line, RuleWrongArity, "'%s' expects %d argument%s but got %d.",
+		function.name.lexeme, len(function.params), plural, len(call.arguments))
+}
@@ -0,0 +1,65 @@
+package golox
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		expected []string
+	}{
+		{"unused local", "fun f() { var x = 1; }", []string{"line 1: Local variable 'x' is never used. (unused-variable)"}},
+		{"used local", "fun f() { var x = 1; print x; }", nil},
+		{"globals are never unused", "var x = 1;", nil},
+		{"shadowed parameter", "fun f(a) { { var a = 1; print a; } }", []string{"line 1: Variable 'a' shadows a parameter of 'f'. (shadowed-parameter)"}},
+		{"unreachable", "fun f() {\n  return 1;\n  print 2;\n}", []string{"line 3: Unreachable code after return. (unreachable-code)"}},
+		{"undeclared global", "fun f() { y = 1; }", []string{"line 1: Assignment to undeclared variable 'y'. (undeclared-global)"}},
+		{"global declared later", "fun f() { y = 1; }\nvar y;", nil},
+		{"natives are declared", "fun f() { clock = 1; }", nil},
+		{"wrong arity", "fun f(a) {}\nf(1, 2);", []string{"line 2: 'f' expects 1 argument but got 2. (wrong-arity)"}},
+		{"redeclared global is unknown", "fun f(a) {}\nvar f;\nf(1, 2);", nil},
+		{"ignore rule", "fun f() { y = 1; } // lint:ignore undeclared-global", nil},
+		{"ignore other rule", "fun f() { y = 1; } // lint:ignore wrong-arity", []string{"line 1: Assignment to undeclared variable 'y'. (undeclared-global)"}},
+		{"ignore next line", "// lint:ignore\nfun f() { y = 1; }", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			warnings, err := Lint(tt.source)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var result []string
+			for _, warning := range warnings {
+				result = append(result, warning.String())
+			}
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Lint(%q) = %q, expected %q", tt.source, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestLintTypeErrors(t *testing.T) {
+	var out bytes.Buffer
+	source := "fun f() { var unused = 1; }\nvar x: string = 1;"
+	warnings, err := Lint(source, WithErrorOutput(&out), WithTypeCheck())
+	if err != ErrSyntax {
+		t.Errorf("Lint error %v, expected ErrSyntax for the type error", err)
+	}
+	if !strings.Contains(out.String(), "[line 2") {
+		t.Errorf("type error not reported, got %q", out.String())
+	}
+	if len(warnings) != 1 || warnings[0].Rule != RuleUnusedVariable {
+		t.Errorf("Lint warnings %v, expected the unused variable", warnings)
+	}
+
+	// without WithTypeCheck the annotations are not checked
+	if warnings, err := Lint(source); err != nil || len(warnings) != 1 {
+		t.Errorf("Lint without type checking warnings %v, error %v", warnings, err)
+	}
+}
@@ -0,0 +1,61 @@
+package golox
+
+import (
+	"strconv"
+	"strings"
+)
+
+// LoxList - ordered collection, created by newList or natives such as listDir
+type LoxList struct {
+	elements []any
+}
+
+func NewLoxList(elements []any) *LoxList {
+	return &LoxList{
+		elements: elements,
+	}
+}
+
+func (l *LoxList) length() int {
+	return len(l.elements)
+}
+
+func (l *LoxList) index(key any) (any, error) {
+	index, err := listIndex(len(l.elements), key)
+	if err != nil {
+		return nil, err
+	}
+	return l.elements[index], nil
+}
+
+func (l *LoxList) setIndex(key any, value any) error {
+	index, err := listIndex(len(l.elements), key)
+	if err != nil {
+		return err
+	}
+	l.elements[index] = value
+	return nil
+}
+
+func (l *LoxList) stringify(i *Interpreter) string {
+	if i.printing[l] {
+		return "[...]"
+	}
+	i.printing[l] = true
+	defer delete(i.printing, l)
+
+	var sb strings.Builder
+	sb.WriteString("[")
+	for n, element := range l.elements {
+		if n > 0 {
+			sb.WriteString(", ")
+		}
+		if s, ok := element.(string); ok {
+			sb.WriteString(strconv.Quote(s))
+		} else {
+			sb.WriteString(i.stringify(element))
+		}
+	}
+	sb.WriteString("]")
+	return sb.String()
+}
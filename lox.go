@@ -2,34 +2,49 @@ package golox
 
 import (
 	"bufio"
+	"context"
+	"errors"
 	"fmt"
 	"os"
 )
 
+// Lox - one interpreter with its globals and error state; not safe for
+// concurrent use, see Pool for running a program from many goroutines
 type Lox struct {
 	hadError        bool
 	hadRuntimeError bool
+	hadExit         bool
+	exitCode        int
 	interpreter     *Interpreter
+	// file - name of the program being compiled or run, for diagnostics
+	file string
 }
 
-func NewLox() *Lox {
+// ErrSyntax - Run found scan, parse or type errors, they were already
+// reported
+var ErrSyntax = errors.New("golox: syntax error")
+
+func NewLox(options ...Option) *Lox {
 	lox := &Lox{
 		hadError:        false,
 		hadRuntimeError: false,
 	}
-	lox.interpreter = NewInterpreter(lox)
+	lox.interpreter = NewInterpreter(lox, options...)
 	return lox
 }
 
+// Main - golox [script [args...]], arguments after the script are
+// passed on to it as the args list
 func (l *Lox) Main(args []string) {
-	if len(args) > 2 {
-		fmt.Println("Usage: golox [script]")
-		return
-	}
-	if len(args) == 2 {
-		if err := l.runFile(args[1]); err != nil {
+	if len(args) >= 2 {
+		WithArgs(args[2:])(l.interpreter)
+		status, err := l.RunFile(args[1])
+		if err != nil {
 			fmt.Println(err)
 		}
+		if status != 0 {
+			os.Exit(status)
+		}
 	} else {
 		if err := l.runPrompt(); err != nil {
 			fmt.Println(err)
@@ -37,20 +52,57 @@ func (l *Lox) Main(args []string) {
 	}
 }
 
-func (l *Lox) runFile(path string) error {
+// Run - execute source for an embedding host; errors are still reported
+// as usual and the outcome is returned as ErrSyntax, a RuntimeError or
+// an ExitError when the script called exit
+func (l *Lox) Run(source string) error {
+	return l.RunContext(context.Background(), source)
+}
+
+// RunContext - Run that stops with a CancelError once ctx is done
+func (l *Lox) RunContext(ctx context.Context, source string) error {
+	l.hadError = false
+	l.hadRuntimeError = false
+	l.hadExit = false
+
+	err := l.run(ctx, "", source)
+	if l.hadError {
+		return ErrSyntax
+	}
+	return err
+}
+
+// Define - expose a Go value to scripts as a global variable, see ToLox
+func (l *Lox) Define(name string, value any) {
+	l.interpreter.globals.define(name, ToLox(value))
+}
+
+// Global - value of a global variable, convert it with FromLox
+func (l *Lox) Global(name string) (any, bool) {
+	value, ok := l.interpreter.globals.values[name]
+	return value, ok
+}
+
+// RunFile - run the script at path and return the status the command
+// line exits with: the code passed to exit, 65 after a syntax error and
+// 70 after a runtime error
+func (l *Lox) RunFile(path string) (int, error) {
 	bytes, err := os.ReadFile(path)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	l.run(string(bytes))
+	l.run(context.Background(), path, string(bytes))
+	if l.hadExit {
+		return l.exitCode, nil
+	}
 	if l.hadError {
-		os.Exit(65)
+		return 65, nil
 	}
 	if l.hadRuntimeError {
-		os.Exit(70)
+		return 70, nil
 	}
-	return nil
+	return 0, nil
 }
 
 func (l *Lox) runPrompt() error {
@@ -65,25 +117,21 @@ func (l *Lox) runPrompt() error {
 			continue
 		}
 
-		l.run(line)
+		l.run(context.Background(), "", line)
+		if l.hadExit {
+			os.Exit(l.exitCode)
+		}
 		l.hadError = false
 	}
 }
 
-func (l *Lox) run(source string) {
-
-	scanner := NewScanner(l, source)
-	tokens := scanner.scanTokens()
-
-	parser := NewParser(l, tokens)
-	statements := parser.Parse()
-
-	if l.hadError {
-		return
+// run - name is the script path, empty for the prompt and embedders
+func (l *Lox) run(ctx context.Context, name string, source string) error {
+	program, err := l.compile(name, source)
+	if err != nil {
+		return nil
 	}
-
-	l.interpreter.interpret(statements)
-
+	return l.interpreter.interpret(ctx, program.statements)
 }
 
 func (l *Lox) Error(line int, message string) {
@@ -91,19 +139,56 @@ func (l *Lox) Error(line int, message string) {
 }
 
 func (l *Lox) RuntimeError(err RuntimeError) {
-	fmt.Printf("%s\n[line %d]\n", err.Message, err.Token.line)
 	l.hadRuntimeError = true
+	if diagnostics := l.interpreter.diagnostics; diagnostics != nil {
+		diagnostics.Add(Diagnostic{
+			Severity: SeverityError,
+			Code:     CodeRuntimeError,
+			File:     l.file,
+			Line:     err.Token.line,
+			Column:   err.Token.column,
+			Message:  err.Message,
+		})
+		return
+	}
+	fmt.Fprintf(l.interpreter.errOut, "%s\n[line %d]\n", err.Message, err.Token.line)
+}
+
+// Exit - the script asked to stop with the given status
+func (l *Lox) Exit(code int) {
+	l.hadExit = true
+	l.exitCode = code
 }
 
 func (l *Lox) ErrorWithToken(token Token, message string) {
+	l.tokenError(token, CodeSyntaxError, message)
+}
+
+// tokenError - ErrorWithToken for the given diagnostic code
+func (l *Lox) tokenError(token Token, code string, message string) {
+	where := fmt.Sprintf(" at '%s'", token.lexeme)
 	if token.kind == TkEof {
-		l.Report(token.line, " at end", message)
-	} else {
-		l.Report(token.line, fmt.Sprintf(" at '%s'", token.lexeme), message)
+		where = " at end"
 	}
+	l.report(code, token.line, token.column, where, message)
 }
 
 func (l *Lox) Report(line int, where string, message string) {
-	fmt.Printf("[line %d] Error%s: %s\n", line, where, message)
+	l.report(CodeSyntaxError, line, 0, where, message)
+}
+
+func (l *Lox) report(code string, line int, column int, where string, message string) {
 	l.hadError = true
+	if diagnostics := l.interpreter.diagnostics; diagnostics != nil {
+		diagnostics.Add(Diagnostic{
+			Severity: SeverityError,
+			Code:     code,
+			File:     l.file,
+			Line:     line,
+			Column:   column,
+			Message:  message,
+		})
+		return
+	}
+	fmt.Fprintf(l.interpreter.errOut, "[line %d] Error%s: %s\n", line, where, message)
 }
@@ -0,0 +1,189 @@
+package golox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tests are written in Lox as top-level functions in *_test.lox files,
+// named like Go tests: test followed by anything but a lower case letter.
+//
+//	fun testAdd() {
+//	  assertEqual(1 + 2, 3);
+//	}
+//
+// Every test runs in a fresh interpreter that first executes the whole
+// file, so globals changed by one test are not seen by the next.
+
+// TestSummary - counts of the tests run by RunTests
+type TestSummary struct {
+	Passed int
+	Failed int
+}
+
+// RunTests - run the tests of the given files, and of the *_test.lox files
+// found below the given directories, writing a report to w; options are
+// applied to every interpreter. The returned error is about finding or
+// reading the files, failed tests only show up in the summary.
+func RunTests(ctx context.Context, w io.Writer, paths []string, options ...Option) (TestSummary, error) {
+	var summary TestSummary
+
+	files, err := findTestFiles(paths)
+	if err != nil {
+		return summary, err
+	}
+	for _, file := range files {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			return summary, err
+		}
+		passed, failed := runTestFile(ctx, w, file, string(source), options)
+		summary.Passed += passed
+		summary.Failed += failed
+	}
+
+	if summary.Failed > 0 {
+		fmt.Fprintf(w, "FAIL: %d passed, %d failed\n", summary.Passed, summary.Failed)
+	} else {
+		fmt.Fprintf(w, "PASS: %d passed\n", summary.Passed)
+	}
+	return summary, nil
+}
+
+func findTestFiles(paths []string) ([]string, error) {
+	var files []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(p, "_test.lox") {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// runTestFile - a file that does not compile counts as one failed test
+func runTestFile(ctx context.Context, w io.Writer, file string, source string, options []Option) (passed int, failed int) {
+	// compiled with a name so that it shows up in coverage reports
+	compiler := NewLox(append(options[:len(options):len(options)], WithErrorOutput(w))...)
+	program, err := compiler.compile(file, source)
+	if err != nil {
+		fmt.Fprintf(w, "FAIL\t%s\t[syntax error]\n", file)
+		return 0, 1
+	}
+
+	tests := testFunctions(program)
+	if len(tests) == 0 {
+		fmt.Fprintf(w, "?\t%s\t[no tests]\n", file)
+		return 0, 0
+	}
+
+	for _, test := range tests {
+		if err := runTest(ctx, program, test, options); err != nil {
+			failure := testFailure(file, test, err)
+			fmt.Fprintf(w, "--- FAIL: %s\n", test.name.lexeme)
+			fmt.Fprintf(w, "    %s:%d: %s\n", failure.File, failure.Line, failure.Message)
+			if diagnostics := compiler.interpreter.diagnostics; diagnostics != nil {
+				failure.Message = test.name.lexeme + ": " + failure.Message
+				diagnostics.Add(failure)
+			}
+			failed++
+		} else {
+			passed++
+		}
+	}
+
+	if failed > 0 {
+		fmt.Fprintf(w, "FAIL\t%s\t%d passed, %d failed\n", file, passed, failed)
+	} else {
+		fmt.Fprintf(w, "ok\t%s\t%d passed\n", file, passed)
+	}
+	return passed, failed
+}
+
+// testFunctions - top-level test functions in declaration order
+func testFunctions(program *Program) []*Function {
+	var tests []*Function
+	for _, stmt := range program.statements {
+		if function, ok := stmt.(*Function); ok && isTestName(function.name.lexeme) {
+			tests = append(tests, function)
+		}
+	}
+	return tests
+}
+
+func isTestName(name string) bool {
+	if !strings.HasPrefix(name, "test") {
+		return false
+	}
+	if len(name) == len("test") {
+		return true
+	}
+	r, _ := utf8.DecodeRuneInString(name[len("test"):])
+	return !unicode.IsLower(r)
+}
+
+func runTest(ctx context.Context, program *Program, test *Function, options []Option) error {
+	if len(test.params) > 0 {
+		return fmt.Errorf("test functions take no parameters")
+	}
+
+	// failures are reported by runTestFile, not by the interpreter
+	options = append(options[:len(options):len(options)], WithErrorOutput(io.Discard))
+	lox := NewLox(options...)
+	if err := lox.RunProgram(ctx, program); err != nil {
+		return err
+	}
+	_, err := lox.Call(test.name.lexeme)
+	return err
+}
+
+// testFailure - located where the runtime error was raised, or at the
+// test declaration when there is no better place
+func testFailure(file string, test *Function, err error) Diagnostic {
+	failure := Diagnostic{
+		Severity: SeverityError,
+		Code:     CodeTestFailure,
+		File:     file,
+		Line:     test.name.line,
+		Column:   test.name.column,
+		Message:  err.Error(),
+	}
+
+	var runtimeErr RuntimeError
+	var exitErr ExitError
+	switch {
+	case errors.As(err, &runtimeErr):
+		failure.Line = runtimeErr.Token.line
+		failure.Column = runtimeErr.Token.column
+		failure.Message = runtimeErr.Message
+	case errors.As(err, &exitErr):
+		failure.Message = fmt.Sprintf("exit(%d) called during test", exitErr.Code)
+	}
+	return failure
+}
@@ -0,0 +1,62 @@
+package golox
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRunTests(t *testing.T) {
+	dir := t.TempDir()
+	source := `var count = 0;
+
+fun testFirst() {
+  count += 1;
+  assertEqual(count, 1);
+}
+
+fun testIsolated() {
+  count += 1;
+  assertEqual(count, 1);
+}
+
+fun testFails() {
+  assert(true);
+  assertEqual(newList(), newList());
+  assert(1 > 2, "one is not greater");
+}
+
+fun testing() {
+  assert(false);
+}
+`
+	if err := os.WriteFile(filepath.Join(dir, "a_test.lox"), []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "helper.lox"), []byte("fun testNot() {}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	diagnostics := NewDiagnostics()
+	summary, err := RunTests(context.Background(), &out, []string{dir}, WithOutput(&out), WithDiagnostics(diagnostics))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if summary.Passed != 2 || summary.Failed != 1 {
+		t.Errorf("RunTests summary %+v, expected 2 passed and 1 failed\n%s", summary, out.String())
+	}
+	expected := "a_test.lox:16: Assertion failed: one is not greater"
+	if !strings.Contains(out.String(), expected) {
+		t.Errorf("RunTests report missing %q:\n%s", expected, out.String())
+	}
+
+	failures := diagnostics.List()
+	if len(failures) != 1 || failures[0].Code != CodeTestFailure || failures[0].Line != 16 ||
+		failures[0].Message != "testFails: Assertion failed: one is not greater" {
+		t.Errorf("RunTests diagnostics %v", failures)
+	}
+}
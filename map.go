@@ -0,0 +1,89 @@
+package golox
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LoxMap - string keyed collection that remembers insertion order
+// so JSON objects keep their key order through a parse/stringify trip
+type LoxMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func NewLoxMap() *LoxMap {
+	return &LoxMap{
+		keys:   []string{},
+		values: make(map[string]any),
+	}
+}
+
+func (m *LoxMap) get(key string) (any, bool) {
+	value, ok := m.values[key]
+	return value, ok
+}
+
+func (m *LoxMap) set(key string, value any) {
+	if _, ok := m.values[key]; !ok {
+		m.keys = append(m.keys, key)
+	}
+	m.values[key] = value
+}
+
+func (m *LoxMap) length() int {
+	return len(m.keys)
+}
+
+func (m *LoxMap) index(key any) (any, error) {
+	k, ok := key.(string)
+	if !ok {
+		return nil, fmt.Errorf("Map key must be a string.")
+	}
+	value, _ := m.get(k)
+	return value, nil
+}
+
+func (m *LoxMap) setIndex(key any, value any) error {
+	k, ok := key.(string)
+	if !ok {
+		return fmt.Errorf("Map key must be a string.")
+	}
+	m.set(k, value)
+	return nil
+}
+
+func (m *LoxMap) keyList() []any {
+	keys := make([]any, len(m.keys))
+	for n, key := range m.keys {
+		keys[n] = key
+	}
+	return keys
+}
+
+func (m *LoxMap) stringify(i *Interpreter) string {
+	if i.printing[m] {
+		return "{...}"
+	}
+	i.printing[m] = true
+	defer delete(i.printing, m)
+
+	var sb strings.Builder
+	sb.WriteString("{")
+	for n, key := range m.keys {
+		if n > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString(strconv.Quote(key))
+		sb.WriteString(": ")
+		value := m.values[key]
+		if s, ok := value.(string); ok {
+			sb.WriteString(strconv.Quote(s))
+		} else {
+			sb.WriteString(i.stringify(value))
+		}
+	}
+	sb.WriteString("}")
+	return sb.String()
+}
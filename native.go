@@ -0,0 +1,48 @@
+package golox
+
+// nativeFunction - a built-in implemented in Go
+// fn may return a plain error, visitCallExpr reports it as a RuntimeError
+// at the call site
+type nativeFunction struct {
+	name          string
+	argCount      int
+	optionalCount int
+	fn            func(interpreter *Interpreter, arguments []any) (any, error)
+}
+
+func NewNativeFunction(name string, arity int,
+	fn func(interpreter *Interpreter, arguments []any) (any, error)) *nativeFunction {
+	return &nativeFunction{
+		name:     name,
+		argCount: arity,
+		fn:       fn,
+	}
+}
+
+// NewNativeFunctionWithOptional - fn always sees arity+optional arguments,
+// the ones left out by the caller are nil
+func NewNativeFunctionWithOptional(name string, arity int, optional int,
+	fn func(interpreter *Interpreter, arguments []any) (any, error)) *nativeFunction {
+	native := NewNativeFunction(name, arity, fn)
+	native.optionalCount = optional
+	return native
+}
+
+func (n *nativeFunction) arity() int {
+	return n.argCount
+}
+
+func (n *nativeFunction) optional() int {
+	return n.optionalCount
+}
+
+func (n *nativeFunction) call(interpreter *Interpreter, arguments []any) (any, error) {
+	for len(arguments) < n.argCount+n.optionalCount {
+		arguments = append(arguments, nil)
+	}
+	return n.fn(interpreter, arguments)
+}
+
+func (n *nativeFunction) String() string {
+	return "<native fn>"
+}
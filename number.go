@@ -0,0 +1,186 @@
+package golox
+
+import "math"
+
+// Lox numbers are either int64 or float64 values.
+// Integer operands stay integral until a result overflows, then the
+// operation is redone in float64. A float operand on either side turns
+// the whole operation into float arithmetic.
+
+func isNumber(value any) bool {
+	switch value.(type) {
+	case int64, float64:
+		return true
+	}
+	return false
+}
+
+func toFloat(value any) float64 {
+	switch n := value.(type) {
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
+
+// arithmetic - apply +, -, *, %, ~/ or ** to two number operands
+// the divisor is expected to be checked against zero beforehand
+func arithmetic(kind TokenType, left any, right any) any {
+	a, leftInt := left.(int64)
+	b, rightInt := right.(int64)
+	if leftInt && rightInt {
+		switch kind {
+		case TkPlus:
+			if r, ok := addInt(a, b); ok {
+				return r
+			}
+		case TkMinus:
+			if r, ok := subInt(a, b); ok {
+				return r
+			}
+		case TkStar:
+			if r, ok := mulInt(a, b); ok {
+				return r
+			}
+		case TkPercent:
+			return a % b
+		case TkTildeSlash:
+			if a != math.MinInt64 || b != -1 {
+				return a / b
+			}
+		case TkStarStar:
+			if b >= 0 {
+				if r, ok := powInt(a, b); ok {
+					return r
+				}
+			}
+		}
+		// overflow or negative exponent, fall back to float
+	}
+
+	x, y := toFloat(left), toFloat(right)
+	switch kind {
+	case TkPlus:
+		return x + y
+	case TkMinus:
+		return x - y
+	case TkStar:
+		return x * y
+	case TkPercent:
+		// remainder takes the sign of the dividend, as in Go
+		return math.Mod(x, y)
+	case TkTildeSlash:
+		// truncate so that a == (a ~/ b) * b + a % b
+		return math.Trunc(x / y)
+	case TkStarStar:
+		return math.Pow(x, y)
+	}
+
+	// unreachable
+	return nil
+}
+
+func negate(value any) any {
+	if n, ok := value.(int64); ok {
+		if n == math.MinInt64 {
+			return -float64(n)
+		}
+		return -n
+	}
+	return -toFloat(value)
+}
+
+// compareNumbers - returns -1, 0 or +1 like strings.Compare
+func compareNumbers(left any, right any) int {
+	a, leftInt := left.(int64)
+	b, rightInt := right.(int64)
+	if leftInt && rightInt {
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	}
+	x, y := toFloat(left), toFloat(right)
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// numbersEqual - integers and floats are equal when they hold the same
+// mathematical value, so 1 == 1.0 but 2^53 + 1 != 2^53 as a float
+func numbersEqual(left any, right any) bool {
+	a, leftInt := left.(int64)
+	b, rightInt := right.(int64)
+	switch {
+	case leftInt && rightInt:
+		return a == b
+	case leftInt:
+		return intEqualsFloat(a, right.(float64))
+	case rightInt:
+		return intEqualsFloat(b, left.(float64))
+	}
+	return left.(float64) == right.(float64)
+}
+
+func intEqualsFloat(i int64, f float64) bool {
+	if math.Trunc(f) != f || f < math.MinInt64 || f >= math.MaxInt64 {
+		return false
+	}
+	return int64(f) == i
+}
+
+func addInt(a, b int64) (int64, bool) {
+	r := a + b
+	if (a > 0 && b > 0 && r < 0) || (a < 0 && b < 0 && r >= 0) {
+		return 0, false
+	}
+	return r, true
+}
+
+func subInt(a, b int64) (int64, bool) {
+	r := a - b
+	if (a >= 0 && b < 0 && r < 0) || (a < 0 && b > 0 && r >= 0) {
+		return 0, false
+	}
+	return r, true
+}
+
+func mulInt(a, b int64) (int64, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	r := a * b
+	if r/b != a || (a == -1 && b == math.MinInt64) || (b == -1 && a == math.MinInt64) {
+		return 0, false
+	}
+	return r, true
+}
+
+// powInt - exponentiation by squaring, exp must not be negative
+func powInt(base, exp int64) (int64, bool) {
+	result := int64(1)
+	for exp > 0 {
+		var ok bool
+		if exp&1 == 1 {
+			if result, ok = mulInt(result, base); !ok {
+				return 0, false
+			}
+		}
+		exp >>= 1
+		if exp > 0 {
+			if base, ok = mulInt(base, base); !ok {
+				return 0, false
+			}
+		}
+	}
+	return result, true
+}
@@ -13,11 +13,13 @@ declaration    -> funDecl
                | statement ;
 
 funDecl        -> "fun" function ;
-function       -> IDENTIFIER "(" parameters? ")" block ;
+function       -> IDENTIFIER "(" parameters? ")" ( ":" type )? block ;
 
-parameters	   -> IDENTIFIER ( "," IDENTIFIER )* ;
+parameters	   -> parameter ( "," parameter )* ;
+parameter      -> IDENTIFIER ( ":" type )? ;
+type           -> IDENTIFIER | "nil" ;
 
-varDecl        -> "var" IDENTIFIER ( "=" expression )? ";" ;
+varDecl        -> "var" IDENTIFIER ( ":" type )? ( "=" expression )? ";" ;
 
 statement      -> exprStmt
 			   | forStmt
@@ -44,18 +46,29 @@ exprStmt       -> expression ";" ;
 printStmt      -> "print" expression ";" ;
 
 expression     -> assignment ;
-assignment     -> IDENTIFIER "=" assignment
-               | logic_or ;
+assignment     -> ( call "." )? IDENTIFIER "=" assignment
+               | ( call "." )? IDENTIFIER ( "+=" | "-=" | "*=" | "/=" ) assignment
+               | conditional ;
 
+conditional    -> coalesce ( "?" expression ":" conditional )? ;
+coalesce       -> logic_or ( "??" logic_or )* ;
 logic_or       -> logic_and ( "or" logic_and )* ;
 logic_and      -> equality ( "and" equality )* ;
 
 equality       -> comparison ( ( "!=" | "==" ) comparison )* ;
-comparison     -> term ( ( ">" | ">=" | "<" | "<=" ) term )* ;
+comparison     -> bit_or ( ( ">" | ">=" | "<" | "<=" ) bit_or )* ;
+bit_or         -> bit_xor ( "|" bit_xor )* ;
+bit_xor        -> bit_and ( "^" bit_and )* ;
+bit_and        -> shift ( "&" shift )* ;
+shift          -> term ( ( "<<" | ">>" ) term )* ;
 term           -> factor ( ( "-" | "+" ) factor )* ;
-factor         -> unary ( ( "/" | "*" ) unary )* ;
-unary          -> ( "!" | "-" ) unary | call ;
-call		   -> primary ( "(" arguments? ")" )* ;
+factor         -> unary ( ( "/" | "*" | "%" | "~/" ) unary )* ; // "~/" is integer division, "//" starts a comment
+unary          -> ( "!" | "-" | "~" ) unary
+               | ( "++" | "--" ) ( call "." )? IDENTIFIER
+               | power ;
+power          -> postfix ( "**" unary )? ;
+postfix        -> call ( "++" | "--" )? ;
+call		   -> primary ( "(" arguments? ")" | "." IDENTIFIER )* ;
                | primary ;
 argument 	   -> expression ("," expression )* ;
 
@@ -68,6 +81,11 @@ type Parser struct {
 	lox     *Lox
 	tokens  []Token
 	current int
+	// lines - line where each statement starts, statements carry no
+	// token of their own
+	lines map[Stmt]int
+	// functions - how deep the parser is in function bodies
+	functions int
 }
 
 type ParseError struct{}
@@ -79,6 +97,7 @@ func NewParser(lox *Lox, tokens []Token) *Parser {
 		lox:     lox,
 		tokens:  tokens,
 		current: 0,
+		lines:   make(map[Stmt]int),
 	}
 }
 
@@ -98,14 +117,19 @@ func (p *Parser) Parse() []Stmt {
 
 func (p *Parser) declaration() (Stmt, error) {
 	if p.match(TkFun) {
-		return p.function("function")
+		line := p.previous().line
+		stmt, err := p.function("function")
+		p.markLine(stmt, line)
+		return stmt, err
 	}
 	if p.match(TkVar) {
+		line := p.previous().line
 		stmt, err := p.varDeclaration()
 		if err != nil {
 			p.synchronize()
 			return nil, nil // cut off error propagation
 		}
+		p.markLine(stmt, line)
 		return stmt, nil
 	}
 
@@ -133,22 +157,30 @@ func (p *Parser) function(kind string) (Stmt, error) {
 	}
 
 	parameters := []*Token{}
+	paramTypes := []*Token{}
+	if !p.check(TkRightParen) {
+		for {
 
-	for {
+			if len(parameters) >= 255 {
+				p.error(p.peek(), "Can't have more than 255 parameters.")
+			}
 
-		if len(parameters) >= 255 {
-			p.error(p.peek(), "Can't have more than 255 parameters.")
-		}
+			t, err := p.consume(TkIdentifier, "Expect parameter name.")
+			if err != nil {
+				return nil, err
+			}
 
-		t, err := p.consume(TkIdentifier, "Expect parameter name.")
-		if err != nil {
-			return nil, err
-		}
+			paramType, err := p.typeAnnotation()
+			if err != nil {
+				return nil, err
+			}
 
-		parameters = append(parameters, t)
+			parameters = append(parameters, t)
+			paramTypes = append(paramTypes, paramType)
 
-		if !p.match(TkComma) {
-			break
+			if !p.match(TkComma) {
+				break
+			}
 		}
 	}
 
@@ -156,6 +188,10 @@ func (p *Parser) function(kind string) (Stmt, error) {
 	if err != nil {
 		return nil, err
 	}
+	returnType, err := p.typeAnnotation()
+	if err != nil {
+		return nil, err
+	}
 
 	_, err = p.consume(TkLeftBrace,
 		fmt.Sprintf("Expect '{' before %s body.", kind))
@@ -163,12 +199,14 @@ func (p *Parser) function(kind string) (Stmt, error) {
 		return nil, err
 	}
 
+	p.functions++
 	body, err := p.block()
+	p.functions--
 	if err != nil {
 		return nil, err
 	}
 
-	return NewFunction(name, parameters, body), nil
+	return NewFunction(name, parameters, paramTypes, returnType, body), nil
 
 }
 
@@ -177,6 +215,10 @@ func (p *Parser) varDeclaration() (Stmt, error) {
 	if err != nil {
 		return nil, err
 	}
+	typeName, err := p.typeAnnotation()
+	if err != nil {
+		return nil, err
+	}
 	var initializer Expr
 	if p.match(TkEqual) {
 		initializer, err = p.expression()
@@ -190,11 +232,44 @@ func (p *Parser) varDeclaration() (Stmt, error) {
 		return nil, err
 	}
 
-	return NewVar(name, initializer), nil
+	return NewVar(name, typeName, initializer), nil
+
+}
 
+// typeAnnotation - the type after an optional ':', nil when there is none;
+// whether the name is a known type is up to the type checker
+func (p *Parser) typeAnnotation() (*Token, error) {
+	if !p.match(TkColon) {
+		return nil, nil
+	}
+	if p.match(TkIdentifier, TkNil) {
+		return p.previous(), nil
+	}
+	return nil, p.error(p.peek(), "Expect type name.")
 }
 
 func (p *Parser) statement() (Stmt, error) {
+	line := p.peek().line
+	stmt, err := p.statementKind()
+	if err != nil {
+		return nil, err
+	}
+	p.markLine(stmt, line)
+	return stmt, nil
+}
+
+// markLine - keep the first line recorded for stmt, desugared statements
+// get the line of the statement they came from
+func (p *Parser) markLine(stmt Stmt, line int) {
+	if stmt == nil {
+		return
+	}
+	if _, ok := p.lines[stmt]; !ok {
+		p.lines[stmt] = line
+	}
+}
+
+func (p *Parser) statementKind() (Stmt, error) {
 	if p.match(TkFor) {
 		return p.forStatement()
 	}
@@ -264,6 +339,9 @@ func (p *Parser) printStatement() (Stmt, error) {
 
 func (p *Parser) returnStatement() (Stmt, error) {
 	keyword := p.previous()
+	if p.functions == 0 {
+		p.error(keyword, "Can't return from top-level code.")
+	}
 	var value Expr = nil
 	var err error = nil
 	if !p.check(TkSemicolon) {
@@ -280,6 +358,7 @@ func (p *Parser) returnStatement() (Stmt, error) {
 }
 
 func (p *Parser) forStatement() (Stmt, error) {
+	line := p.previous().line
 	_, err := p.consume(TkLeftParen, "Expect '(' after 'for'.")
 	if err != nil {
 		return nil, err
@@ -330,9 +409,11 @@ func (p *Parser) forStatement() (Stmt, error) {
 	}
 
 	if increment != nil {
+		step := NewExpression(increment)
+		p.markLine(step, line)
 		statements := []Stmt{
 			body,
-			NewExpression(increment),
+			step,
 		}
 		body = NewBlock(statements)
 	}
@@ -341,8 +422,10 @@ func (p *Parser) forStatement() (Stmt, error) {
 		condition = NewLiteral(true)
 	}
 	body = NewWhile(condition, body)
+	p.markLine(body, line)
 
 	if initializer != nil {
+		p.markLine(initializer, line)
 		body = NewBlock([]Stmt{
 			initializer,
 			body,
@@ -405,7 +488,7 @@ func (p *Parser) expression() (Expr, error) {
 }
 
 func (p *Parser) assignment() (Expr, error) {
-	expr, err := p.or()
+	expr, err := p.conditional()
 	if err != nil {
 		return nil, err
 	}
@@ -417,15 +500,77 @@ func (p *Parser) assignment() (Expr, error) {
 		}
 
 		// only l-value is allowed
-		v, ok := expr.(*Variable)
-		if ok {
-			name := v.name
-			return NewAssign(name, value), nil
+		switch target := expr.(type) {
+		case *Variable:
+			return NewAssign(target.name, value), nil
+		case *Get:
+			return NewSet(target.object, target.name, value), nil
 		}
 
 		err = p.error(equals, "Invalid assignment target.")
 		return nil, err
 	}
+	if p.match(TkPlusEqual, TkMinusEqual, TkStarEqual, TkSlashEqual) {
+		operator := p.previous()
+		value, err := p.assignment()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isAssignable(expr) {
+			return nil, p.error(operator, "Invalid assignment target.")
+		}
+		return NewCompoundAssign(expr, operator, value), nil
+	}
+	return expr, nil
+}
+
+// isAssignable - l-value check shared by compound assignment and ++/--
+func (p *Parser) isAssignable(expr Expr) bool {
+	switch expr.(type) {
+	case *Variable, *Get:
+		return true
+	}
+	return false
+}
+
+// conditional  ->  coalesce ( "?" expression ":" conditional )? ;
+func (p *Parser) conditional() (Expr, error) {
+	expr, err := p.coalesce()
+	if err != nil {
+		return nil, err
+	}
+	if p.match(TkQuestion) {
+		thenBranch, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		_, err = p.consume(TkColon, "Expect ':' after then branch of conditional expression.")
+		if err != nil {
+			return nil, err
+		}
+		elseBranch, err := p.conditional()
+		if err != nil {
+			return nil, err
+		}
+		expr = NewConditional(expr, thenBranch, elseBranch)
+	}
+	return expr, nil
+}
+
+// coalesce  ->  logic_or ( "??" logic_or )* ;
+func (p *Parser) coalesce() (Expr, error) {
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	for p.match(TkQuestionQuestion) {
+		operator := p.previous()
+		right, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		expr = NewLogical(expr, operator, right)
+	}
 	return expr, nil
 }
 
@@ -482,13 +627,81 @@ func (p *Parser) equality() (Expr, error) {
 	return expr, nil
 }
 
-// comparison  ->  term ( ( ">" | ">=" | "<" | "<=" ) term )* ;
+// comparison  ->  bit_or ( ( ">" | ">=" | "<" | "<=" ) bit_or )* ;
 func (p *Parser) comparison() (Expr, error) {
-	expr, err := p.term()
+	expr, err := p.bitOr()
 	if err != nil {
 		return nil, err
 	}
 	for p.match(TkGreater, TkGreaterEqual, TkLess, TkLessEqual) {
+		operator := p.previous()
+		right, err := p.bitOr()
+		if err != nil {
+			return nil, err
+		}
+		expr = NewBinary(expr, operator, right)
+	}
+	return expr, nil
+}
+
+// bit_or  ->  bit_xor ( "|" bit_xor )* ;
+func (p *Parser) bitOr() (Expr, error) {
+	expr, err := p.bitXor()
+	if err != nil {
+		return nil, err
+	}
+	for p.match(TkPipe) {
+		operator := p.previous()
+		right, err := p.bitXor()
+		if err != nil {
+			return nil, err
+		}
+		expr = NewBinary(expr, operator, right)
+	}
+	return expr, nil
+}
+
+// bit_xor  ->  bit_and ( "^" bit_and )* ;
+func (p *Parser) bitXor() (Expr, error) {
+	expr, err := p.bitAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.match(TkCaret) {
+		operator := p.previous()
+		right, err := p.bitAnd()
+		if err != nil {
+			return nil, err
+		}
+		expr = NewBinary(expr, operator, right)
+	}
+	return expr, nil
+}
+
+// bit_and  ->  shift ( "&" shift )* ;
+func (p *Parser) bitAnd() (Expr, error) {
+	expr, err := p.shift()
+	if err != nil {
+		return nil, err
+	}
+	for p.match(TkAmpersand) {
+		operator := p.previous()
+		right, err := p.shift()
+		if err != nil {
+			return nil, err
+		}
+		expr = NewBinary(expr, operator, right)
+	}
+	return expr, nil
+}
+
+// shift  ->  term ( ( "<<" | ">>" ) term )* ;
+func (p *Parser) shift() (Expr, error) {
+	expr, err := p.term()
+	if err != nil {
+		return nil, err
+	}
+	for p.match(TkLessLess, TkGreaterGreater) {
 		operator := p.previous()
 		right, err := p.term()
 		if err != nil {
@@ -516,13 +729,13 @@ func (p *Parser) term() (Expr, error) {
 	return expr, nil
 }
 
-// factor  ->  unary ( ( "/" | "*" ) unary )* ;
+// factor  ->  unary ( ( "/" | "*" | "%" | "~/" ) unary )* ;
 func (p *Parser) factor() (Expr, error) {
 	expr, err := p.unary()
 	if err != nil {
 		return nil, err
 	}
-	for p.match(TkSlash, TkStar) {
+	for p.match(TkSlash, TkStar, TkPercent, TkTildeSlash) {
 		operator := p.previous()
 		right, err := p.unary()
 		if err != nil {
@@ -533,10 +746,10 @@ func (p *Parser) factor() (Expr, error) {
 	return expr, nil
 }
 
-// unary  ->  ( "!" | "-" ) unary
-//        | call ;
+// unary  ->  ( "!" | "-" | "~" ) unary
+//        | power ;
 func (p *Parser) unary() (Expr, error) {
-	if p.match(TkBang, TkMinus) {
+	if p.match(TkBang, TkMinus, TkTilde) {
 		operator := p.previous()
 		right, err := p.unary()
 		if err != nil {
@@ -544,10 +757,56 @@ func (p *Parser) unary() (Expr, error) {
 		}
 		return NewUnary(operator, right), nil
 	}
-	return p.call()
+	if p.match(TkPlusPlus, TkMinusMinus) {
+		operator := p.previous()
+		target, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		if !p.isAssignable(target) {
+			return nil, p.error(operator, "Invalid increment target.")
+		}
+		return NewIncrement(target, operator, true), nil
+	}
+	return p.power()
+}
+
+// power  ->  postfix ( "**" unary )? ;
+// right-associative and binds tighter than a unary on its left,
+// so -2 ** 2 is -(2 ** 2) while 2 ** -1 still parses
+func (p *Parser) power() (Expr, error) {
+	expr, err := p.postfix()
+	if err != nil {
+		return nil, err
+	}
+	if p.match(TkStarStar) {
+		operator := p.previous()
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		expr = NewBinary(expr, operator, right)
+	}
+	return expr, nil
+}
+
+// postfix  ->  call ( "++" | "--" )? ;
+func (p *Parser) postfix() (Expr, error) {
+	expr, err := p.call()
+	if err != nil {
+		return nil, err
+	}
+	if p.match(TkPlusPlus, TkMinusMinus) {
+		operator := p.previous()
+		if !p.isAssignable(expr) {
+			return nil, p.error(operator, "Invalid increment target.")
+		}
+		return NewIncrement(expr, operator, false), nil
+	}
+	return expr, nil
 }
 
-// call		   -> primary ( "(" arguments? ")" )* ;
+// call		   -> primary ( "(" arguments? ")" | "." IDENTIFIER )* ;
 //             | primary ;
 func (p *Parser) call() (Expr, error) {
 	expr, err := p.primary()
@@ -560,6 +819,12 @@ func (p *Parser) call() (Expr, error) {
 			if err != nil {
 				return nil, err
 			}
+		} else if p.match(TkDot) {
+			name, err := p.consume(TkIdentifier, "Expect property name after '.'.")
+			if err != nil {
+				return nil, err
+			}
+			expr = NewGet(expr, name)
 		} else {
 			break
 		}
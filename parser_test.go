@@ -0,0 +1,79 @@
+package golox
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParser_FunctionParameters(t *testing.T) {
+	tests := []struct {
+		source string
+		params []string
+	}{
+		{"fun none() {}", []string{}},
+		{"fun one(a) {}", []string{"a"}},
+		{"fun two(a, b) {}", []string{"a", "b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			lox := NewLox()
+			statements := NewParser(lox, NewScanner(lox, tt.source).scanTokens()).Parse()
+			if lox.hadError || len(statements) != 1 {
+				t.Fatalf("parse %q failed", tt.source)
+			}
+			function, ok := statements[0].(*Function)
+			if !ok {
+				t.Fatalf("parse %q result %T, expected *Function", tt.source, statements[0])
+			}
+			if len(function.params) != len(tt.params) {
+				t.Fatalf("parse %q got %d parameters, expected %d",
+					tt.source, len(function.params), len(tt.params))
+			}
+			for n, param := range function.params {
+				if param.lexeme != tt.params[n] {
+					t.Errorf("parameter %d is %s, expected %s", n, param.lexeme, tt.params[n])
+				}
+			}
+		})
+	}
+}
+
+func TestParser_TopLevelReturn(t *testing.T) {
+	tests := []struct {
+		source string
+		ok     bool
+	}{
+		{"return;", false},
+		{"if (true) { return 1; }", false},
+		{"fun f() { return 1; }", true},
+		{"fun f() { fun g() { return; } return g; }", true},
+		{"fun f() {} return;", false},
+	}
+
+	for _, tt := range tests {
+		lox := NewLox(WithErrorOutput(io.Discard))
+		NewParser(lox, NewScanner(lox, tt.source).scanTokens()).Parse()
+		if lox.hadError == tt.ok {
+			t.Errorf("parse %q error %v, expected %v", tt.source, lox.hadError, !tt.ok)
+		}
+	}
+}
+
+// TestInterpreter_TopLevelReturn - an AST built without the parser is
+// reported as a runtime error rather than panicking the host
+func TestInterpreter_TopLevelReturn(t *testing.T) {
+	keyword := &Token{kind: TkReturn, lexeme: "return", line: 3}
+	program := &Program{statements: []Stmt{NewReturn(keyword, NewLiteral(1.0))}}
+	var out bytes.Buffer
+	err := NewLox(WithErrorOutput(&out)).RunProgram(context.Background(), program)
+	if _, ok := err.(RuntimeError); !ok {
+		t.Fatalf("RunProgram error %T %v, expected a RuntimeError", err, err)
+	}
+	if !strings.Contains(out.String(), "Can't return from top-level code.") {
+		t.Errorf("reported %q", out.String())
+	}
+}
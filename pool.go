@@ -0,0 +1,146 @@
+package golox
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Program - source scanned and parsed once; the AST is never modified
+// while it runs, so one Program can be shared by many goroutines
+type Program struct {
+	statements []Stmt
+	// lines - where each statement starts, from the parser
+	lines  map[Stmt]int
+	source string
+	// name - the script path, empty for source given directly
+	name string
+}
+
+// Compile - parse source into a Program, type checked with WithTypeCheck;
+// syntax and type errors are reported as usual, or where WithErrorOutput
+// says, and ErrSyntax is returned
+func Compile(source string, options ...Option) (*Program, error) {
+	return NewLox(options...).compile("", source)
+}
+
+// compile - a named program is registered with the coverage collector
+// of the interpreter, if there is one
+func (l *Lox) compile(name string, source string) (*Program, error) {
+	program, err := l.parse(name, source)
+	if err != nil {
+		return nil, err
+	}
+	if l.interpreter.typeCheck {
+		NewTypeChecker(l).Check(program.statements)
+		if l.hadError {
+			return nil, ErrSyntax
+		}
+	}
+
+	if coverage := l.interpreter.coverage; coverage != nil && name != "" {
+		coverage.add(name, program)
+	}
+	if profiler := l.interpreter.profiler; profiler != nil {
+		profiler.add(name, program)
+	}
+	return program, nil
+}
+
+// parse - compile without the type checker, whatever the options
+func (l *Lox) parse(name string, source string) (*Program, error) {
+	l.file = name
+	tokens := NewScanner(l, source).scanTokens()
+	parser := NewParser(l, tokens)
+	statements := parser.Parse()
+	if l.hadError {
+		return nil, ErrSyntax
+	}
+	return &Program{
+		statements: statements,
+		lines:      parser.lines,
+		source:     source,
+		name:       name,
+	}, nil
+}
+
+// RunProgram - RunContext for an already compiled Program; coverage and
+// profiles only know the lines of programs compiled with the same options
+func (l *Lox) RunProgram(ctx context.Context, program *Program) error {
+	l.hadError = false
+	l.hadRuntimeError = false
+	l.hadExit = false
+	l.file = program.name
+	return l.interpreter.interpret(ctx, program.statements)
+}
+
+// Pool - runs one Program in isolated interpreters, meant for servers
+// running a script per request. A Lox is not safe for concurrent use, so
+// every goroutine gets a new one from Acquire: fresh globals, error state
+// and natives, while the AST is shared. Interpreters are never reused,
+// because a script may change its globals; the pool only limits how many
+// run at once.
+//
+// Options are applied to every instance, so values they share, such as
+// the reader of WithStdin or a TimeSource, must be safe for concurrent use.
+// WithCoverage and WithProfiler are not and NewPool rejects them.
+type Pool struct {
+	program *Program
+	options []Option
+	slots   chan struct{}
+	mu      sync.Mutex
+	active  map[*Lox]bool
+}
+
+// ErrPoolOption - NewPool was given an option whose value cannot be
+// shared by concurrent instances
+var ErrPoolOption = errors.New("golox: WithCoverage and WithProfiler cannot be used with a Pool")
+
+// NewPool - at most size instances are acquired at the same time
+func NewPool(program *Program, size int, options ...Option) (*Pool, error) {
+	if size < 1 {
+		size = 1
+	}
+	probe := NewLox(options...).interpreter
+	if probe.coverage != nil || probe.profiler != nil {
+		return nil, ErrPoolOption
+	}
+	return &Pool{
+		program: program,
+		options: options,
+		slots:   make(chan struct{}, size),
+		active:  make(map[*Lox]bool),
+	}, nil
+}
+
+// Acquire - wait for a free slot, then return a new Lox that has already
+// run the program, so its functions are ready for Call
+func (p *Pool) Acquire(ctx context.Context) (*Lox, error) {
+	select {
+	case p.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, NewCancelError(ctx.Err())
+	}
+
+	lox := NewLox(p.options...)
+	if err := lox.RunProgram(ctx, p.program); err != nil {
+		<-p.slots
+		return nil, err
+	}
+
+	p.mu.Lock()
+	p.active[lox] = true
+	p.mu.Unlock()
+	return lox, nil
+}
+
+// Release - give back the slot of an instance from Acquire
+func (p *Pool) Release(lox *Lox) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.active[lox] {
+		return
+	}
+	delete(p.active, lox)
+	<-p.slots
+}
@@ -0,0 +1,117 @@
+package golox
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sync"
+	"testing"
+)
+
+// run with go test -race to check that instances share no mutable state
+func TestPool_IsolatedInstances(t *testing.T) {
+	program, err := Compile(`
+		var counter = 0;
+		var seen = newList();
+		fun handle(id) {
+			counter++;
+			push(seen, id);
+			var total = 0;
+			for (var i = 0; i < 100; i++) total += i;
+			return format(id, "d") + ":" + format(counter, "d") + ":" + format(total, "d");
+		}
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool, err := NewPool(program, 4, WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	errs := make(chan error, 32)
+	for n := 0; n < 32; n++ {
+		wg.Add(1)
+		go func(id int) {
+			defer wg.Done()
+			lox, err := pool.Acquire(context.Background())
+			if err != nil {
+				errs <- err
+				return
+			}
+			defer pool.Release(lox)
+
+			for call := 1; call <= 3; call++ {
+				result, err := lox.Call("handle", id)
+				if err != nil {
+					errs <- err
+					return
+				}
+				// every instance starts from counter = 0
+				expected := fmt.Sprintf("%d:%d:4950", id, call)
+				if result != expected {
+					errs <- fmt.Errorf("handle(%d) result %v, expected %s", id, result, expected)
+				}
+			}
+		}(n)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Error(err)
+	}
+}
+
+func TestPool_AcquireWaitsForSlot(t *testing.T) {
+	program, err := Compile(`var x = 1;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool, err := NewPool(program, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	first, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := pool.Acquire(ctx); err == nil {
+		t.Errorf("Acquire on a full pool succeeded, expected cancellation")
+	}
+
+	pool.Release(first)
+	second, err := pool.Acquire(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool.Release(second)
+}
+
+// TestCompile_TypeCheck - untyped programs compile as they always did,
+// only WithTypeCheck rejects them before they run
+func TestCompile_TypeCheck(t *testing.T) {
+	source := `if (false) print nil + 1;
+var s: string = 1;`
+	if _, err := Compile(source); err != nil {
+		t.Errorf("Compile error %v, expected no type checking by default", err)
+	}
+	if _, err := Compile(source, WithErrorOutput(io.Discard), WithTypeCheck()); err != ErrSyntax {
+		t.Errorf("Compile with WithTypeCheck error %v, expected ErrSyntax", err)
+	}
+}
+
+func TestPool_RejectsSharedCollectors(t *testing.T) {
+	program, err := Compile(`var x = 1;`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, option := range []Option{WithCoverage(NewCoverage()), WithProfiler(NewProfiler())} {
+		if _, err := NewPool(program, 2, WithSeed(1), option); err != ErrPoolOption {
+			t.Errorf("NewPool error %v, expected ErrPoolOption", err)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package golox
+
+import (
+	"compress/gzip"
+	"io"
+	"sort"
+)
+
+// WriteProfile - gzipped profile.proto as read by go tool pprof. Every
+// sample is a Lox call stack with two values, how many events were
+// charged to it and the nanoseconds they took; a location is one line
+// of one function.
+func (p *Profiler) WriteProfile(w io.Writer) error {
+	names := newStringTable()
+	var locations, functions protoBuffer
+	locationIDs := make(map[profileFrame]uint64)
+	functionIDs := make(map[profileFunction]uint64)
+
+	functionID := func(f profileFunction) uint64 {
+		if id, ok := functionIDs[f]; ok {
+			return id
+		}
+		id := uint64(len(functionIDs) + 1)
+		functionIDs[f] = id
+		var function protoBuffer
+		function.uint64(1, id)
+		function.int64(2, names.index(f.name))
+		function.int64(3, names.index(f.name))
+		function.int64(4, names.index(f.file))
+		function.int64(5, int64(f.line))
+		functions.message(5, &function)
+		return id
+	}
+	locationID := func(frame profileFrame) uint64 {
+		if id, ok := locationIDs[frame]; ok {
+			return id
+		}
+		id := uint64(len(locationIDs) + 1)
+		locationIDs[frame] = id
+		var line protoBuffer
+		line.uint64(1, functionID(frame.function))
+		line.int64(2, int64(frame.line))
+		var location protoBuffer
+		location.uint64(1, id)
+		location.message(4, &line)
+		locations.message(4, &location)
+		return id
+	}
+
+	var profile protoBuffer
+	for _, valueType := range [][2]string{{"samples", "count"}, {"time", "nanoseconds"}} {
+		var vt protoBuffer
+		vt.int64(1, names.index(valueType[0]))
+		vt.int64(2, names.index(valueType[1]))
+		profile.message(1, &vt)
+	}
+
+	// map order is random, sort for a reproducible file
+	samples := p.samples()
+	sort.SliceStable(samples, func(a, b int) bool {
+		return samples[a].nanos > samples[b].nanos
+	})
+	for _, s := range samples {
+		// pprof wants the innermost frame first
+		var ids []uint64
+		for node := s; node.parent != nil; node = node.parent {
+			ids = append(ids, locationID(node.frame))
+		}
+		var sample protoBuffer
+		sample.packedUint64(1, ids)
+		sample.packedInt64(2, []int64{s.count, s.nanos})
+		profile.message(2, &sample)
+	}
+
+	profile.bytes = append(profile.bytes, locations.bytes...)
+	profile.bytes = append(profile.bytes, functions.bytes...)
+	for _, s := range names.values {
+		profile.string(6, s)
+	}
+	profile.int64(9, p.started.UnixNano())
+	profile.int64(10, int64(p.last.Sub(p.started)))
+	var period protoBuffer
+	period.int64(1, names.index("time"))
+	period.int64(2, names.index("nanoseconds"))
+	// the string table is already written, both strings are in it
+	profile.message(11, &period)
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(profile.bytes); err != nil {
+		return err
+	}
+	return gz.Close()
+}
+
+// stringTable - the profile refers to strings by index, 0 is ""
+type stringTable struct {
+	values  []string
+	indexes map[string]int64
+}
+
+func newStringTable() *stringTable {
+	return &stringTable{
+		values:  []string{""},
+		indexes: map[string]int64{"": 0},
+	}
+}
+
+func (t *stringTable) index(s string) int64 {
+	if n, ok := t.indexes[s]; ok {
+		return n
+	}
+	n := int64(len(t.values))
+	t.values = append(t.values, s)
+	t.indexes[s] = n
+	return n
+}
+
+// protoBuffer - just enough of the protobuf wire format for profile.proto
+type protoBuffer struct {
+	bytes []byte
+}
+
+func (b *protoBuffer) varint(v uint64) {
+	for v >= 0x80 {
+		b.bytes = append(b.bytes, byte(v)|0x80)
+		v >>= 7
+	}
+	b.bytes = append(b.bytes, byte(v))
+}
+
+func (b *protoBuffer) key(field int, wireType int) {
+	b.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (b *protoBuffer) uint64(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+	b.key(field, 0)
+	b.varint(v)
+}
+
+func (b *protoBuffer) int64(field int, v int64) {
+	if v == 0 {
+		return
+	}
+	b.key(field, 0)
+	b.varint(uint64(v))
+}
+
+func (b *protoBuffer) string(field int, s string) {
+	b.key(field, 2)
+	b.varint(uint64(len(s)))
+	b.bytes = append(b.bytes, s...)
+}
+
+func (b *protoBuffer) message(field int, m *protoBuffer) {
+	b.key(field, 2)
+	b.varint(uint64(len(m.bytes)))
+	b.bytes = append(b.bytes, m.bytes...)
+}
+
+func (b *protoBuffer) packedUint64(field int, values []uint64) {
+	var packed protoBuffer
+	for _, v := range values {
+		packed.varint(v)
+	}
+	b.message(field, &packed)
+}
+
+func (b *protoBuffer) packedInt64(field int, values []int64) {
+	var packed protoBuffer
+	for _, v := range values {
+		packed.varint(uint64(v))
+	}
+	b.message(field, &packed)
+}
@@ -2,15 +2,30 @@ package golox
 
 import (
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 )
 
-type AstPrinter struct{}
+// AstPrinter - prints a tree either Lisp-like, showing its structure, or
+// as Lox source that parses back into the same tree
+type AstPrinter struct {
+	// source - print Lox source instead of the Lisp-like form
+	source bool
+	// depth - nesting of the statement being printed, for indentation
+	depth int
+}
 
 func NewAstPrinter() *AstPrinter {
 	return &AstPrinter{}
 }
 
+// NewSourcePrinter - AstPrinter that prints Lox source; the desugared
+// for loop comes back as the equivalent while loop
+func NewSourcePrinter() *AstPrinter {
+	return &AstPrinter{source: true}
+}
+
 func (p *AstPrinter) Print(expr Expr) string {
 	str, err := expr.Accept(p)
 	if err != nil {
@@ -20,15 +35,210 @@ func (p *AstPrinter) Print(expr Expr) string {
 	return str.(string)
 }
 
+func (p *AstPrinter) PrintStmt(stmt Stmt) string {
+	str, err := stmt.Accept(p)
+	if err != nil {
+		return "error" + err.Error()
+	}
+	return str.(string)
+}
+
+// PrintProgram - every statement of program on its own line; fails on
+// literals Lox has no syntax for, like a string with a quote in it
+func (p *AstPrinter) PrintProgram(program *Program) (string, error) {
+	var sb strings.Builder
+	for _, stmt := range program.statements {
+		str, err := stmt.Accept(p)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(str.(string) + "\n")
+	}
+	return sb.String(), nil
+}
+
+// Precedence of each expression in source, following the grammar rules
+// of the parser from the loosest to the tightest binding
+const (
+	precAssignment = iota + 1
+	precConditional
+	precCoalesce
+	precOr
+	precAnd
+	precEquality
+	precComparison
+	precBitOr
+	precBitXor
+	precBitAnd
+	precShift
+	precTerm
+	precFactor
+	precUnary
+	precPower
+	precPostfix
+	precCall
+	precPrimary
+)
+
+var binaryPrecedence = map[TokenType]int{
+	TkQuestionQuestion: precCoalesce,
+	TkOr:               precOr,
+	TkAnd:              precAnd,
+	TkBangEqual:        precEquality,
+	TkEqualEqual:       precEquality,
+	TkGreater:          precComparison,
+	TkGreaterEqual:     precComparison,
+	TkLess:             precComparison,
+	TkLessEqual:        precComparison,
+	TkPipe:             precBitOr,
+	TkCaret:            precBitXor,
+	TkAmpersand:        precBitAnd,
+	TkLessLess:         precShift,
+	TkGreaterGreater:   precShift,
+	TkMinus:            precTerm,
+	TkPlus:             precTerm,
+	TkSlash:            precFactor,
+	TkStar:             precFactor,
+	TkPercent:          precFactor,
+	TkTildeSlash:       precFactor,
+	TkStarStar:         precPower,
+}
+
+func precedence(expr Expr) int {
+	switch e := expr.(type) {
+	case *Assign, *Set, *CompoundAssign:
+		return precAssignment
+	case *Conditional:
+		return precConditional
+	case *Binary:
+		return binaryPrecedence[e.operator.kind]
+	case *Logical:
+		return binaryPrecedence[e.operator.kind]
+	case *Unary:
+		return precUnary
+	case *Increment:
+		if e.prefix {
+			return precUnary
+		}
+		return precPostfix
+	case *Call, *Get:
+		return precCall
+	case *Literal:
+		// a negative number only comes out of a unary minus
+		switch v := e.value.(type) {
+		case int64:
+			if v < 0 {
+				return precUnary
+			}
+		case float64:
+			if math.Signbit(v) {
+				return precUnary
+			}
+		}
+	}
+	return precPrimary
+}
+
+// operand - expr, in source in parentheses when it binds looser than the
+// position it is printed at needs
+func (p *AstPrinter) operand(expr Expr, minimum int) (string, error) {
+	str, err := expr.Accept(p)
+	if err != nil {
+		return "", err
+	}
+	if p.source && precedence(expr) < minimum {
+		return "(" + str.(string) + ")", nil
+	}
+	return str.(string), nil
+}
+
+// infix - left operator right, the side that may not hold another
+// operator of the same precedence gets one level more
+func (p *AstPrinter) infix(left Expr, operator *Token, right Expr) (any, error) {
+	prec := binaryPrecedence[operator.kind]
+	leftPrec, rightPrec := prec, prec+1
+	if operator.kind == TkStarStar {
+		// right-associative, and the left side may not be a unary
+		leftPrec, rightPrec = precPostfix, precUnary
+	}
+	l, err := p.operand(left, leftPrec)
+	if err != nil {
+		return nil, err
+	}
+	r, err := p.operand(right, rightPrec)
+	if err != nil {
+		return nil, err
+	}
+	return l + " " + operator.lexeme + " " + r, nil
+}
+
 func (p *AstPrinter) visitLogicalExpr(expr *Logical) (any, error) {
+	if p.source {
+		return p.infix(expr.left, expr.operator, expr.right)
+	}
 	return p.parenthesize(expr.operator.lexeme, expr.left, expr.right)
 }
 
 func (p *AstPrinter) visitBinaryExpr(expr *Binary) (any, error) {
+	if p.source {
+		return p.infix(expr.left, expr.operator, expr.right)
+	}
 	return p.parenthesize(expr.operator.lexeme, expr.left, expr.right)
 }
 
+func (p *AstPrinter) visitConditionalExpr(expr *Conditional) (any, error) {
+	if p.source {
+		condition, err := p.operand(expr.condition, precCoalesce)
+		if err != nil {
+			return nil, err
+		}
+		thenBranch, err := p.operand(expr.thenBranch, precAssignment)
+		if err != nil {
+			return nil, err
+		}
+		elseBranch, err := p.operand(expr.elseBranch, precConditional)
+		if err != nil {
+			return nil, err
+		}
+		return condition + " ? " + thenBranch + " : " + elseBranch, nil
+	}
+	return p.parenthesize("?:", expr.condition, expr.thenBranch, expr.elseBranch)
+}
+
+func (p *AstPrinter) visitGetExpr(expr *Get) (any, error) {
+	object, err := p.operand(expr.object, precCall)
+	if err != nil {
+		return nil, err
+	}
+	if p.source {
+		return object + "." + expr.name.lexeme, nil
+	}
+	return fmt.Sprintf("(. %s %s)", object, expr.name.lexeme), nil
+}
+
+func (p *AstPrinter) visitSetExpr(expr *Set) (any, error) {
+	object, err := p.operand(expr.object, precCall)
+	if err != nil {
+		return nil, err
+	}
+	value, err := p.operand(expr.value, precAssignment)
+	if err != nil {
+		return nil, err
+	}
+	if p.source {
+		return object + "." + expr.name.lexeme + " = " + value, nil
+	}
+	return fmt.Sprintf("(= (. %s %s) %s)", object, expr.name.lexeme, value), nil
+}
+
 func (p *AstPrinter) visitGroupingExpr(expr *Grouping) (any, error) {
+	if p.source {
+		expression, err := expr.expression.Accept(p)
+		if err != nil {
+			return nil, err
+		}
+		return "(" + expression.(string) + ")", nil
+	}
 	return p.parenthesize("group", expr.expression)
 }
 
@@ -44,30 +254,315 @@ func (p *AstPrinter) visitLiteralExpr(expr *Literal) (any, error) {
 			return "false", nil
 		}
 	case string:
-		return vt, nil
+		if !p.source {
+			return vt, nil
+		}
+		if strings.Contains(vt, `"`) {
+			return nil, fmt.Errorf("golox: cannot print string %q as a literal", vt)
+		}
+		return `"` + vt + `"`, nil
 	case int:
 		return fmt.Sprintf("%d", vt), nil
+	case int64:
+		return fmt.Sprintf("%d", vt), nil
 	case float64:
-		return fmt.Sprintf("%.2f", vt), nil
+		return floatLiteral(vt)
 	}
 	// TODO - better error handling
 	return "err", nil
 }
 
+// floatLiteral - a float as the scanner reads it, always with a fraction
+// so it does not come back as an integer
+func floatLiteral(value float64) (string, error) {
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		return "", fmt.Errorf("golox: cannot print %s as a literal", formatFloat(value))
+	}
+	text := strconv.FormatFloat(value, 'f', -1, 64)
+	if !strings.Contains(text, ".") {
+		text += ".0"
+	}
+	return text, nil
+}
+
 func (p *AstPrinter) visitUnaryExpr(expr *Unary) (any, error) {
+	if p.source {
+		right, err := p.operand(expr.right, precUnary)
+		if err != nil {
+			return nil, err
+		}
+		// - -x, not the decrement --x
+		if strings.HasPrefix(right, "-") && expr.operator.lexeme == "-" {
+			right = " " + right
+		}
+		return expr.operator.lexeme + right, nil
+	}
 	return p.parenthesize(expr.operator.lexeme, expr.right)
 }
 
 func (p *AstPrinter) visitVariableExpr(expr *Variable) (any, error) {
+	if p.source {
+		return expr.name.lexeme, nil
+	}
 	return "(var)", nil
 }
 
 func (p *AstPrinter) visitAssignExpr(expr *Assign) (any, error) {
-	return fmt.Sprintf("(assign %s:%s)", expr.name.lexeme, expr.value), nil
+	value, err := p.operand(expr.value, precAssignment)
+	if err != nil {
+		return nil, err
+	}
+	if p.source {
+		return expr.name.lexeme + " = " + value, nil
+	}
+	return fmt.Sprintf("(= %s %s)", expr.name.lexeme, value), nil
+}
+
+func (p *AstPrinter) visitCompoundAssignExpr(expr *CompoundAssign) (any, error) {
+	if p.source {
+		target, err := p.operand(expr.target, precCall)
+		if err != nil {
+			return nil, err
+		}
+		value, err := p.operand(expr.value, precAssignment)
+		if err != nil {
+			return nil, err
+		}
+		return target + " " + expr.operator.lexeme + " " + value, nil
+	}
+	return p.parenthesize(expr.operator.lexeme, expr.target, expr.value)
+}
+
+func (p *AstPrinter) visitIncrementExpr(expr *Increment) (any, error) {
+	if p.source {
+		target, err := p.operand(expr.target, precCall)
+		if err != nil {
+			return nil, err
+		}
+		if expr.prefix {
+			return expr.operator.lexeme + target, nil
+		}
+		return target + expr.operator.lexeme, nil
+	}
+	if expr.prefix {
+		return p.parenthesize(expr.operator.lexeme, expr.target)
+	}
+	return p.parenthesize("postfix"+expr.operator.lexeme, expr.target)
 }
 
 func (p *AstPrinter) visitCallExpr(expr *Call) (any, error) {
-	return fmt.Sprintf("(call %s)", expr.callee), nil
+	callee, err := p.operand(expr.callee, precCall)
+	if err != nil {
+		return nil, err
+	}
+	arguments := make([]string, len(expr.arguments))
+	for n, argument := range expr.arguments {
+		if arguments[n], err = p.operand(argument, precAssignment); err != nil {
+			return nil, err
+		}
+	}
+	if p.source {
+		return callee + "(" + strings.Join(arguments, ", ") + ")", nil
+	}
+	return "(call " + strings.Join(append([]string{callee}, arguments...), " ") + ")", nil
+}
+
+func (p *AstPrinter) visitBlockStmt(stmt *Block) (any, error) {
+	if p.source {
+		return p.block(stmt.statements)
+	}
+	return p.parenthesizeStmts("block", stmt.statements...)
+}
+
+func (p *AstPrinter) visitExpressionStmt(stmt *Expression) (any, error) {
+	if p.source {
+		expression, err := stmt.expression.Accept(p)
+		if err != nil {
+			return nil, err
+		}
+		return expression.(string) + ";", nil
+	}
+	return p.parenthesize(";", stmt.expression)
+}
+
+func (p *AstPrinter) visitFunctionStmt(stmt *Function) (any, error) {
+	params := make([]string, len(stmt.params))
+	for n, param := range stmt.params {
+		params[n] = p.annotated(param.lexeme, stmt.paramTypes[n])
+	}
+	if p.source {
+		body, err := p.block(stmt.body)
+		if err != nil {
+			return nil, err
+		}
+		signature := stmt.name.lexeme + "(" + strings.Join(params, ", ") + ")"
+		return "fun " + p.annotated(signature, stmt.returnType) + " " + body, nil
+	}
+	name := fmt.Sprintf("fun %s (%s)", p.annotated(stmt.name.lexeme, stmt.returnType), strings.Join(params, " "))
+	return p.parenthesizeStmts(name, stmt.body...)
+}
+
+func (p *AstPrinter) visitIfStmt(stmt *If) (any, error) {
+	if !p.source {
+		condition, err := stmt.condition.Accept(p)
+		if err != nil {
+			return nil, err
+		}
+		branches := []Stmt{stmt.thenBranch}
+		if stmt.elseBranch != nil {
+			branches = append(branches, stmt.elseBranch)
+		}
+		return p.parenthesizeStmts("if "+condition.(string), branches...)
+	}
+
+	condition, err := stmt.condition.Accept(p)
+	if err != nil {
+		return nil, err
+	}
+	thenBranch := stmt.thenBranch
+	if inner, ok := thenBranch.(*If); ok && inner.elseBranch == nil && stmt.elseBranch != nil {
+		// the else would go to the inner if
+		thenBranch = NewBlock([]Stmt{inner})
+	}
+	str, err := p.body(thenBranch)
+	if err != nil {
+		return nil, err
+	}
+	str = "if (" + condition.(string) + ")" + str
+	if stmt.elseBranch == nil {
+		return str, nil
+	}
+
+	if _, ok := thenBranch.(*Block); ok {
+		str += " else"
+	} else {
+		str += "\n" + p.indent() + "else"
+	}
+	if _, ok := stmt.elseBranch.(*If); ok {
+		elseBranch, err := stmt.elseBranch.Accept(p)
+		if err != nil {
+			return nil, err
+		}
+		return str + " " + elseBranch.(string), nil
+	}
+	elseBranch, err := p.body(stmt.elseBranch)
+	if err != nil {
+		return nil, err
+	}
+	return str + elseBranch, nil
+}
+
+func (p *AstPrinter) visitPrintStmt(stmt *Print) (any, error) {
+	if p.source {
+		expression, err := stmt.expression.Accept(p)
+		if err != nil {
+			return nil, err
+		}
+		return "print " + expression.(string) + ";", nil
+	}
+	return p.parenthesize("print", stmt.expression)
+}
+
+func (p *AstPrinter) visitReturnStmt(stmt *Return) (any, error) {
+	if stmt.value == nil {
+		if p.source {
+			return "return;", nil
+		}
+		return "(return)", nil
+	}
+	if p.source {
+		value, err := stmt.value.Accept(p)
+		if err != nil {
+			return nil, err
+		}
+		return "return " + value.(string) + ";", nil
+	}
+	return p.parenthesize("return", stmt.value)
+}
+
+func (p *AstPrinter) visitVarStmt(stmt *Var) (any, error) {
+	name := p.annotated(stmt.name.lexeme, stmt.typeName)
+	if stmt.initializer == nil {
+		if p.source {
+			return "var " + name + ";", nil
+		}
+		return "(var " + name + ")", nil
+	}
+	if p.source {
+		initializer, err := stmt.initializer.Accept(p)
+		if err != nil {
+			return nil, err
+		}
+		return "var " + name + " = " + initializer.(string) + ";", nil
+	}
+	return p.parenthesize("var "+name, stmt.initializer)
+}
+
+func (p *AstPrinter) visitWhileStmt(stmt *While) (any, error) {
+	condition, err := stmt.condition.Accept(p)
+	if err != nil {
+		return nil, err
+	}
+	if !p.source {
+		return p.parenthesizeStmts("while "+condition.(string), stmt.body)
+	}
+	body, err := p.body(stmt.body)
+	if err != nil {
+		return nil, err
+	}
+	return "while (" + condition.(string) + ")" + body, nil
+}
+
+// annotated - name with its type annotation, if any
+func (p *AstPrinter) annotated(name string, typeName *Token) string {
+	if typeName == nil {
+		return name
+	}
+	if p.source {
+		return name + ": " + typeName.lexeme
+	}
+	return name + ":" + typeName.lexeme
+}
+
+func (p *AstPrinter) indent() string {
+	return strings.Repeat("  ", p.depth)
+}
+
+// block - statements in braces, one per line and indented a level deeper
+func (p *AstPrinter) block(statements []Stmt) (string, error) {
+	if len(statements) == 0 {
+		return "{}", nil
+	}
+	var sb strings.Builder
+	sb.WriteString("{\n")
+	p.depth++
+	for _, stmt := range statements {
+		str, err := stmt.Accept(p)
+		if err != nil {
+			p.depth--
+			return "", err
+		}
+		sb.WriteString(p.indent() + str.(string) + "\n")
+	}
+	p.depth--
+	sb.WriteString(p.indent() + "}")
+	return sb.String(), nil
+}
+
+// body - of if, else or while; a block stays on the line of the keyword,
+// other statements go on the next line
+func (p *AstPrinter) body(stmt Stmt) (string, error) {
+	if block, ok := stmt.(*Block); ok {
+		str, err := p.block(block.statements)
+		return " " + str, err
+	}
+	p.depth++
+	defer func() { p.depth-- }()
+	str, err := stmt.Accept(p)
+	if err != nil {
+		return "", err
+	}
+	return "\n" + p.indent() + str.(string), nil
 }
 
 // parenthesize - private helper function
@@ -86,3 +581,19 @@ func (p *AstPrinter) parenthesize(name string, exprs ...Expr) (string, error) {
 	sb.WriteString(")")
 	return sb.String(), nil
 }
+
+func (p *AstPrinter) parenthesizeStmts(name string, stmts ...Stmt) (string, error) {
+	var sb strings.Builder
+	sb.WriteString("(" + name)
+	for _, stmt := range stmts {
+		sb.WriteString(" ")
+
+		v, err := stmt.Accept(p)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteString(v.(string))
+	}
+	sb.WriteString(")")
+	return sb.String(), nil
+}
@@ -1,6 +1,11 @@
 package golox
 
 import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
 	"testing"
 )
 
@@ -27,3 +32,185 @@ func TestAstPrinter_Print(t *testing.T) {
 		}
 	})
 }
+
+func TestAstPrinter_PrintOperators(t *testing.T) {
+	tests := []struct {
+		source   string
+		expected string
+	}{
+		{"7 % 2", "(% 7 2)"},
+		{"7 ~/ 2 * 3", "(* (~/ 7 2) 3)"},
+		{"2 ** 3 ** 2", "(** 2 (** 3 2))"},
+		{"-2 ** 2", "(- (** 2 2))"},
+		{"~1 & 2 | 3 ^ 4", "(| (& (~ 1) 2) (^ 3 4))"},
+		{"1 << 2 + 3", "(<< 1 (+ 2 3))"},
+		{"1 | 2 == 3", "(== (| 1 2) 3)"},
+		{"a += 1", "(+= (var) 1)"},
+		{"++a", "(++ (var))"},
+		{"-a--", "(- (postfix-- (var)))"},
+		{"1 ? 2 : 3 ? 4 : 5", "(?: 1 2 (?: 3 4 5))"},
+		{"nil ?? 1 or 2 ? 3 : 4", "(?: (?? nil (or 1 2)) 3 4)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			lox := NewLox()
+			tokens := NewScanner(lox, tt.source).scanTokens()
+			expr, err := NewParser(lox, tokens).expression()
+			if err != nil {
+				t.Fatalf("parse %q failed", tt.source)
+			}
+			result := NewAstPrinter().Print(expr)
+			if result != tt.expected {
+				t.Errorf("AstPrinter_Print result %s, expected %s",
+					result,
+					tt.expected,
+				)
+			}
+		})
+	}
+}
+
+func TestAstPrinter_PrintStmt(t *testing.T) {
+	source := "fun add(a: number, b): number { return a + b; }\n" +
+		"var x = add(1, 2.5);\n" +
+		"if (x > 3) { print x; } else x = nil;\n" +
+		"for (var i = 0; i < 2; i++) print i;\n"
+	expected := []string{
+		"(fun add:number (a:number b) (return (+ (var) (var))))",
+		"(var x (call (var) 1 2.5))",
+		"(if (> (var) 3) (block (print (var))) (; (= x nil)))",
+		"(block (var i 0) (while (< (var) 2) (block (print (var)) (; (postfix++ (var))))))",
+	}
+
+	lox := NewLox()
+	statements := NewParser(lox, NewScanner(lox, source).scanTokens()).Parse()
+	if lox.hadError || len(statements) != len(expected) {
+		t.Fatalf("parse failed: %d statements", len(statements))
+	}
+	for n, stmt := range statements {
+		if result := NewAstPrinter().PrintStmt(stmt); result != expected[n] {
+			t.Errorf("AstPrinter_PrintStmt result %s, expected %s", result, expected[n])
+		}
+	}
+}
+
+func TestSourcePrinter_Print(t *testing.T) {
+	tok := func(kind TokenType, lexeme string) *Token {
+		return NewToken(kind, lexeme, nil, 1)
+	}
+	x := NewVariable(tok(TkIdentifier, "x"))
+	one := NewLiteral(int64(1))
+	tests := []struct {
+		expr     Expr
+		expected string
+	}{
+		{NewBinary(NewBinary(one, tok(TkPlus, "+"), x), tok(TkStar, "*"), x), "(1 + x) * x"},
+		{NewBinary(x, tok(TkMinus, "-"), NewBinary(x, tok(TkMinus, "-"), one)), "x - (x - 1)"},
+		{NewBinary(NewBinary(x, tok(TkStarStar, "**"), one), tok(TkStarStar, "**"), x), "(x ** 1) ** x"},
+		{NewBinary(NewUnary(tok(TkMinus, "-"), x), tok(TkStarStar, "**"), x), "(-x) ** x"},
+		{NewUnary(tok(TkMinus, "-"), NewUnary(tok(TkMinus, "-"), x)), "- -x"},
+		{NewUnary(tok(TkMinus, "-"), NewIncrement(x, tok(TkMinusMinus, "--"), true)), "- --x"},
+		{NewBinary(NewLiteral(int64(-2)), tok(TkStarStar, "**"), one), "(-2) ** 1"},
+		{NewConditional(NewConditional(x, one, one), NewAssign(x.name, one), x), "(x ? 1 : 1) ? x = 1 : x"},
+		{NewGet(NewBinary(x, tok(TkPlus, "+"), one), tok(TkIdentifier, "y")), "(x + 1).y"},
+		{NewCall(x, tok(TkRightParen, ")"), []Expr{NewAssign(x.name, one), NewLiteral("s")}), `x(x = 1, "s")`},
+		{NewLiteral(1.0), "1.0"},
+		{NewLiteral(0.1), "0.1"},
+		{NewLiteral(1e21), "1000000000000000000000.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			result := NewSourcePrinter().Print(tt.expr)
+			if result != tt.expected {
+				t.Errorf("SourcePrinter_Print result %s, expected %s", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSourcePrinter_DanglingElse(t *testing.T) {
+	inner := NewIf(NewLiteral(true), NewPrint(NewLiteral(int64(1))), nil)
+	stmt := NewIf(NewLiteral(false), inner, NewPrint(NewLiteral(int64(2))))
+	expected := "if (false) {\n  if (true)\n    print 1;\n} else\n  print 2;"
+	if result := NewSourcePrinter().PrintStmt(stmt); result != expected {
+		t.Errorf("SourcePrinter_PrintStmt result\n%s\nexpected\n%s", result, expected)
+	}
+}
+
+// TestSourcePrinter_RoundTrip - parsing the printed source of every script
+// gives the same tree, apart from lines
+func TestSourcePrinter_RoundTrip(t *testing.T) {
+	var scripts []string
+	for _, pattern := range []string{"*.lox", "*.txt"} {
+		matches, err := filepath.Glob(filepath.Join("test", pattern))
+		if err != nil {
+			t.Fatal(err)
+		}
+		scripts = append(scripts, matches...)
+	}
+	for _, script := range scripts {
+		script := script
+		t.Run(filepath.Base(script), func(t *testing.T) {
+			source, err := os.ReadFile(script)
+			if err != nil {
+				t.Fatal(err)
+			}
+			program, ok := parseProgram(string(source))
+			if !ok {
+				t.Skip("does not parse")
+			}
+			printed, err := NewSourcePrinter().PrintProgram(program)
+			if err != nil {
+				t.Fatal(err)
+			}
+			reparsed, ok := parseProgram(printed)
+			if !ok {
+				t.Fatalf("printed source does not parse:\n%s", printed)
+			}
+			if want, got := astShape(t, program), astShape(t, reparsed); !reflect.DeepEqual(want, got) {
+				t.Errorf("tree changed, printed source:\n%s", printed)
+			}
+			if again, _ := NewSourcePrinter().PrintProgram(reparsed); again != printed {
+				t.Errorf("printing is not stable:\n%s\n%s", printed, again)
+			}
+		})
+	}
+}
+
+func parseProgram(source string) (*Program, bool) {
+	lox := NewLox(WithErrorOutput(io.Discard))
+	parser := NewParser(lox, NewScanner(lox, source).scanTokens())
+	statements := parser.Parse()
+	return &Program{statements: statements, lines: parser.lines}, !lox.hadError
+}
+
+// astShape - the JSON form of program without any positions
+func astShape(t *testing.T, program *Program) any {
+	data, err := json.Marshal(program)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var shape any
+	if err := json.Unmarshal(data, &shape); err != nil {
+		t.Fatal(err)
+	}
+	var strip func(node any)
+	strip = func(node any) {
+		switch n := node.(type) {
+		case map[string]any:
+			delete(n, "line")
+			delete(n, "column")
+			for _, child := range n {
+				strip(child)
+			}
+		case []any:
+			for _, child := range n {
+				strip(child)
+			}
+		}
+	}
+	strip(shape)
+	return shape
+}
@@ -0,0 +1,76 @@
+package golox
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+)
+
+// WithArgs - command line arguments exposed to the script as the args list
+func WithArgs(args []string) Option {
+	return func(i *Interpreter) {
+		elements := make([]any, len(args))
+		for n, arg := range args {
+			elements[n] = arg
+		}
+		i.globals.define("args", NewLoxList(elements))
+	}
+}
+
+// WithEnvLookup - enable getenv, os.LookupEnv exposes the host environment
+func WithEnvLookup(lookup func(key string) (string, bool)) Option {
+	return func(i *Interpreter) {
+		i.envLookup = lookup
+	}
+}
+
+// WithStdin - source read by readLine(stdin), os.Stdin by default
+func WithStdin(reader io.Reader) Option {
+	return func(i *Interpreter) {
+		i.globals.define("stdin", newStdin(reader))
+	}
+}
+
+func defineProcessNatives(globals *Environment) {
+	globals.define("args", NewLoxList([]any{}))
+	globals.define("stdin", newStdin(os.Stdin))
+	globals.define("getenv", NewNativeFunction("getenv", 1, nativeGetenv))
+	globals.define("exit", NewNativeFunctionWithOptional("exit", 0, 1, nativeExit))
+}
+
+func newStdin(reader io.Reader) *loxFile {
+	return &loxFile{
+		name:   "stdin",
+		file:   io.NopCloser(reader),
+		reader: bufio.NewReader(reader),
+	}
+}
+
+// getenv(name) - value of an environment variable, nil when it is unset
+func nativeGetenv(i *Interpreter, arguments []any) (any, error) {
+	name, ok := arguments[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("Environment variable name must be a string.")
+	}
+	if i.envLookup == nil {
+		return nil, fmt.Errorf("Environment access is disabled.")
+	}
+	value, ok := i.envLookup(name)
+	if !ok {
+		return nil, nil
+	}
+	return value, nil
+}
+
+// exit(code?) - stop the script, the host exits with code (0 by default)
+func nativeExit(i *Interpreter, arguments []any) (any, error) {
+	if arguments[0] == nil {
+		return nil, NewExitError(0)
+	}
+	code, ok := arguments[0].(int64)
+	if !ok || code < 0 || code > 255 {
+		return nil, fmt.Errorf("Exit code must be an integer between 0 and 255.")
+	}
+	return nil, NewExitError(int(code))
+}
@@ -0,0 +1,320 @@
+package golox
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+// Profiler - tracing profiler for Lox code. The time between two events
+// (a statement or a call starting or finishing) is charged to the Lox
+// call stack at that moment, which gives exact per-function and per-line
+// times and the call graph written by WriteProfile. Not safe for
+// concurrent use.
+type Profiler struct {
+	now     func() time.Time
+	started time.Time
+	last    time.Time
+	// root - the empty stack, current - the running one; every node of
+	// the tree is one stack with the time charged to it
+	root     *profileNode
+	current  *profileNode
+	calls    map[profileFunction]int
+	hits     map[profilePosition]int
+	position map[Stmt]profilePosition
+	programs map[*Program]bool
+}
+
+// profileFunction - a Lox function by name and declaration, natives have
+// no file or line
+type profileFunction struct {
+	name string
+	file string
+	line int
+}
+
+type profilePosition struct {
+	file string
+	line int
+}
+
+func (p profilePosition) String() string {
+	if p.file == "" {
+		return fmt.Sprintf("line %d", p.line)
+	}
+	return fmt.Sprintf("%s:%d", p.file, p.line)
+}
+
+// profileFrame - a running function and the line it is at
+type profileFrame struct {
+	function profileFunction
+	line     int
+}
+
+// profileNode - the stack of its parent plus one frame
+type profileNode struct {
+	frame    profileFrame
+	parent   *profileNode
+	children map[profileFrame]*profileNode
+	count    int64
+	nanos    int64
+}
+
+func (n *profileNode) child(frame profileFrame) *profileNode {
+	child, ok := n.children[frame]
+	if !ok {
+		child = &profileNode{
+			frame:    frame,
+			parent:   n,
+			children: make(map[profileFrame]*profileNode),
+		}
+		n.children[frame] = child
+	}
+	return child
+}
+
+// stack - frames from the outermost call to n
+func (n *profileNode) stack() []profileFrame {
+	var frames []profileFrame
+	for node := n; node.parent != nil; node = node.parent {
+		frames = append([]profileFrame{node.frame}, frames...)
+	}
+	return frames
+}
+
+// scriptFunction - the frame of top-level code, named like the entry
+// point of a Go program; pprof drops names in angle brackets
+const scriptFunction = "main"
+
+func NewProfiler() *Profiler {
+	now := time.Now()
+	root := &profileNode{children: make(map[profileFrame]*profileNode)}
+	return &Profiler{
+		now:      time.Now,
+		started:  now,
+		last:     now,
+		root:     root,
+		current:  root,
+		calls:    make(map[profileFunction]int),
+		hits:     make(map[profilePosition]int),
+		position: make(map[Stmt]profilePosition),
+		programs: make(map[*Program]bool),
+	}
+}
+
+// WithProfiler - record time and calls of everything the interpreter runs
+func WithProfiler(p *Profiler) Option {
+	return func(i *Interpreter) {
+		i.profiler = p
+	}
+}
+
+// add - learn where the statements of program are
+func (p *Profiler) add(name string, program *Program) {
+	if p.programs[program] {
+		return
+	}
+	p.programs[program] = true
+	for stmt, line := range program.lines {
+		p.position[stmt] = profilePosition{name, line}
+	}
+}
+
+// tick - charge the time since the last event to the current stack
+func (p *Profiler) tick() {
+	now := p.now()
+	if p.current != p.root {
+		p.current.count++
+		p.current.nanos += int64(now.Sub(p.last))
+	}
+	p.last = now
+}
+
+// moveTo - the running function is now at line
+func (p *Profiler) moveTo(line int) {
+	frame := p.current.frame
+	frame.line = line
+	p.current = p.current.parent.child(frame)
+}
+
+// enterStatement - returns what leaveStatement needs to restore, -1 when
+// the statement started a new top-level frame
+func (p *Profiler) enterStatement(stmt Stmt) int {
+	p.tick()
+	position, known := p.position[stmt]
+	if known {
+		p.hits[position]++
+	}
+	if p.current == p.root {
+		p.current = p.root.child(profileFrame{
+			function: profileFunction{name: scriptFunction, file: position.file},
+			line:     position.line,
+		})
+		return -1
+	}
+	previous := p.current.frame.line
+	if known {
+		p.moveTo(position.line)
+	}
+	return previous
+}
+
+func (p *Profiler) leaveStatement(previous int) {
+	p.tick()
+	if previous == -1 {
+		p.current = p.root
+		return
+	}
+	p.moveTo(previous)
+}
+
+// enterCall - line is the call site, 0 when the host made the call
+func (p *Profiler) enterCall(callee LoxCallable, line int) {
+	p.tick()
+	if p.current != p.root && line > 0 {
+		p.moveTo(line)
+	}
+	function := p.function(callee)
+	p.calls[function]++
+	p.current = p.current.child(profileFrame{function: function, line: function.line})
+}
+
+func (p *Profiler) leaveCall() {
+	p.tick()
+	p.current = p.current.parent
+}
+
+func (p *Profiler) function(callee LoxCallable) profileFunction {
+	switch f := callee.(type) {
+	case *loxFunction:
+		return profileFunction{
+			name: f.declaration.name.lexeme,
+			file: p.position[f.declaration].file,
+			line: f.declaration.name.line,
+		}
+	case *nativeFunction:
+		return profileFunction{name: f.name}
+	case *goFunction:
+		return profileFunction{name: f.name}
+	case *clock:
+		return profileFunction{name: "clock"}
+	}
+	return profileFunction{name: fmt.Sprint(callee)}
+}
+
+func (f profileFunction) String() string {
+	if f.line == 0 {
+		return f.name
+	}
+	return fmt.Sprintf("%s (%s)", f.name, profilePosition{f.file, f.line})
+}
+
+// samples - every stack that was charged time, innermost frame last
+func (p *Profiler) samples() []*profileNode {
+	var nodes []*profileNode
+	var walk func(n *profileNode)
+	walk = func(n *profileNode) {
+		if n.count > 0 {
+			nodes = append(nodes, n)
+		}
+		for _, child := range n.children {
+			walk(child)
+		}
+	}
+	walk(p.root)
+	return nodes
+}
+
+// functionTimes - flat time spent in each function itself and cumulative
+// time including its callees, recursion is only counted once
+func (p *Profiler) functionTimes() (flat map[profileFunction]int64, cum map[profileFunction]int64) {
+	flat = make(map[profileFunction]int64)
+	cum = make(map[profileFunction]int64)
+	for _, sample := range p.samples() {
+		flat[sample.frame.function] += sample.nanos
+		seen := make(map[profileFunction]bool)
+		for _, frame := range sample.stack() {
+			if !seen[frame.function] {
+				seen[frame.function] = true
+				cum[frame.function] += sample.nanos
+			}
+		}
+	}
+	return flat, cum
+}
+
+// WriteReport - functions sorted by their own time, then the lines that
+// took the most time
+func (p *Profiler) WriteReport(w io.Writer) {
+	flat, cum := p.functionTimes()
+	var total int64
+	for _, nanos := range flat {
+		total += nanos
+	}
+
+	functions := make([]profileFunction, 0, len(cum))
+	for function := range cum {
+		functions = append(functions, function)
+	}
+	sort.Slice(functions, func(a, b int) bool {
+		fa, fb := functions[a], functions[b]
+		if flat[fa] != flat[fb] {
+			return flat[fa] > flat[fb]
+		}
+		if cum[fa] != cum[fb] {
+			return cum[fa] > cum[fb]
+		}
+		return fa.String() < fb.String()
+	})
+
+	fmt.Fprintf(w, "Total: %s\n\n", time.Duration(total))
+	fmt.Fprintf(w, "%12s %7s %12s %7s %8s  %s\n", "flat", "flat%", "cum", "cum%", "calls", "function")
+	for _, function := range functions {
+		fmt.Fprintf(w, "%12s %7s %12s %7s %8d  %s\n",
+			time.Duration(flat[function]), percent64(flat[function], total),
+			time.Duration(cum[function]), percent64(cum[function], total),
+			p.calls[function], function)
+	}
+
+	lineTimes := make(map[profilePosition]int64)
+	for _, sample := range p.samples() {
+		top := sample.frame
+		lineTimes[profilePosition{top.function.file, top.line}] += sample.nanos
+	}
+	for position := range p.hits {
+		if _, ok := lineTimes[position]; !ok {
+			lineTimes[position] = 0
+		}
+	}
+	lines := make([]profilePosition, 0, len(lineTimes))
+	for position := range lineTimes {
+		if position.line > 0 {
+			lines = append(lines, position)
+		}
+	}
+	sort.Slice(lines, func(a, b int) bool {
+		la, lb := lines[a], lines[b]
+		if lineTimes[la] != lineTimes[lb] {
+			return lineTimes[la] > lineTimes[lb]
+		}
+		if la.file != lb.file {
+			return la.file < lb.file
+		}
+		return la.line < lb.line
+	})
+
+	fmt.Fprintf(w, "\n%12s %7s %8s  %s\n", "time", "time%", "hits", "line")
+	for _, position := range lines {
+		fmt.Fprintf(w, "%12s %7s %8d  %s\n",
+			time.Duration(lineTimes[position]), percent64(lineTimes[position], total),
+			p.hits[position], position)
+	}
+}
+
+func percent64(part, whole int64) string {
+	if whole == 0 {
+		return "0.0%"
+	}
+	return fmt.Sprintf("%.1f%%", 100*float64(part)/float64(whole))
+}
@@ -0,0 +1,77 @@
+package golox
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestProfiler(t *testing.T) {
+	source := `fun fib(n) {
+  if (n <= 1) return n;
+  return fib(n - 2) + fib(n - 1);
+}
+print fib(5);
+`
+	path := filepath.Join(t.TempDir(), "fib.lox")
+	if err := os.WriteFile(path, []byte(source), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// every event takes exactly one millisecond
+	profiler := NewProfiler()
+	clock := profiler.started
+	profiler.now = func() time.Time {
+		clock = clock.Add(time.Millisecond)
+		return clock
+	}
+
+	var out bytes.Buffer
+	lox := NewLox(WithProfiler(profiler), WithOutput(&out))
+	if _, err := lox.RunFile(path); err != nil {
+		t.Fatal(err)
+	}
+
+	fib := profileFunction{"fib", path, 1}
+	if calls := profiler.calls[fib]; calls != 15 {
+		t.Errorf("fib called %d times, expected 15", calls)
+	}
+	flat, cum := profiler.functionTimes()
+	// recursive calls must not count the same time twice
+	if cum[fib] != flat[fib] || flat[fib] == 0 {
+		t.Errorf("fib flat %d cum %d, expected them equal for a function calling only itself", flat[fib], cum[fib])
+	}
+	main := profileFunction{name: scriptFunction, file: path}
+	if flat[main]+flat[fib] != cum[main] {
+		t.Errorf("main cum %d, expected the total %d", cum[main], flat[main]+flat[fib])
+	}
+
+	var report bytes.Buffer
+	profiler.WriteReport(&report)
+	if !strings.Contains(report.String(), "15  fib ("+path+":1)") {
+		t.Errorf("report missing fib with 15 calls:\n%s", report.String())
+	}
+
+	var profile bytes.Buffer
+	if err := profiler.WriteProfile(&profile); err != nil {
+		t.Fatal(err)
+	}
+	gz, err := gzip.NewReader(&profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"fib", "main", "nanoseconds", path} {
+		if !bytes.Contains(data, []byte(name)) {
+			t.Errorf("profile string table missing %q", name)
+		}
+	}
+}
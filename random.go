@@ -0,0 +1,82 @@
+package golox
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+)
+
+// WithSeed - seed the random natives so runs are reproducible,
+// otherwise every interpreter starts from a time based seed
+func WithSeed(seed int64) Option {
+	return func(i *Interpreter) {
+		i.random = rand.New(rand.NewSource(seed))
+	}
+}
+
+func defineRandomNatives(globals *Environment) {
+	globals.define("random", NewNativeFunction("random", 0, nativeRandom))
+	globals.define("randomInt", NewNativeFunction("randomInt", 2, nativeRandomInt))
+	globals.define("shuffle", NewNativeFunction("shuffle", 1, nativeShuffle))
+	globals.define("choice", NewNativeFunction("choice", 1, nativeChoice))
+	globals.define("seed", NewNativeFunction("seed", 1, nativeSeed))
+}
+
+// random() - float in [0, 1)
+func nativeRandom(i *Interpreter, arguments []any) (any, error) {
+	return i.random.Float64(), nil
+}
+
+// randomInt(lo, hi) - integer in [lo, hi], both ends included
+func nativeRandomInt(i *Interpreter, arguments []any) (any, error) {
+	lo, ok := arguments[0].(int64)
+	if !ok {
+		return nil, fmt.Errorf("randomInt bounds must be integers.")
+	}
+	hi, ok := arguments[1].(int64)
+	if !ok {
+		return nil, fmt.Errorf("randomInt bounds must be integers.")
+	}
+	if lo > hi {
+		return nil, fmt.Errorf("randomInt lower bound %d is above upper bound %d.", lo, hi)
+	}
+	span, ok := subInt(hi, lo)
+	if !ok || span == math.MaxInt64 {
+		return nil, fmt.Errorf("randomInt range is too large.")
+	}
+	return lo + i.random.Int63n(span+1), nil
+}
+
+// shuffle(list) - reorder the list in place
+func nativeShuffle(i *Interpreter, arguments []any) (any, error) {
+	list, ok := arguments[0].(*LoxList)
+	if !ok {
+		return nil, fmt.Errorf("Can only shuffle lists.")
+	}
+	i.random.Shuffle(len(list.elements), func(a, b int) {
+		list.elements[a], list.elements[b] = list.elements[b], list.elements[a]
+	})
+	return nil, nil
+}
+
+// choice(list) - random element of a non-empty list
+func nativeChoice(i *Interpreter, arguments []any) (any, error) {
+	list, ok := arguments[0].(*LoxList)
+	if !ok {
+		return nil, fmt.Errorf("Can only choose from lists.")
+	}
+	if len(list.elements) == 0 {
+		return nil, fmt.Errorf("Cannot choose from an empty list.")
+	}
+	return list.elements[i.random.Intn(len(list.elements))], nil
+}
+
+// seed(n) - restart the generator from n
+func nativeSeed(i *Interpreter, arguments []any) (any, error) {
+	n, ok := arguments[0].(int64)
+	if !ok {
+		return nil, fmt.Errorf("Seed must be an integer.")
+	}
+	i.random.Seed(n)
+	return nil, nil
+}
@@ -0,0 +1,116 @@
+package golox
+
+import (
+	"math"
+	"reflect"
+	"testing"
+)
+
+// draw - the values of a few random natives, in order
+func draw(interpreter *Interpreter) []any {
+	var values []any
+	for n := 0; n < 5; n++ {
+		f, _ := nativeRandom(interpreter, nil)
+		r, _ := nativeRandomInt(interpreter, []any{int64(1), int64(100)})
+		values = append(values, f, r)
+	}
+	list := NewLoxList([]any{int64(1), int64(2), int64(3), int64(4), int64(5)})
+	nativeShuffle(interpreter, []any{list})
+	choice, _ := nativeChoice(interpreter, []any{list})
+	return append(values, list.elements, choice)
+}
+
+func TestRandomNatives_WithSeed(t *testing.T) {
+	first := draw(NewInterpreter(NewLox(), WithSeed(42)))
+	second := draw(NewInterpreter(NewLox(), WithSeed(42)))
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("same seed gave %v and %v, expected the same sequence", first, second)
+	}
+	if other := draw(NewInterpreter(NewLox(), WithSeed(43))); reflect.DeepEqual(first, other) {
+		t.Errorf("seeds 42 and 43 gave the same sequence %v", first)
+	}
+
+	// seed() restarts the generator like WithSeed
+	interpreter := NewInterpreter(NewLox(), WithSeed(1))
+	draw(interpreter)
+	if _, err := nativeSeed(interpreter, []any{int64(42)}); err != nil {
+		t.Fatal(err)
+	}
+	if again := draw(interpreter); !reflect.DeepEqual(first, again) {
+		t.Errorf("seed(42) gave %v, expected %v", again, first)
+	}
+	if _, err := nativeSeed(interpreter, []any{1.5}); err == nil {
+		t.Errorf("seed(1.5) allowed, expected an integer")
+	}
+}
+
+func TestRandomNatives_Bounds(t *testing.T) {
+	interpreter := NewInterpreter(NewLox(), WithSeed(7))
+
+	seen := make(map[int64]bool)
+	for n := 0; n < 1000; n++ {
+		value, err := nativeRandomInt(interpreter, []any{int64(-2), int64(2)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		r := value.(int64)
+		if r < -2 || r > 2 {
+			t.Fatalf("randomInt(-2, 2) result %d out of range", r)
+		}
+		seen[r] = true
+
+		f, _ := nativeRandom(interpreter, nil)
+		if f.(float64) < 0 || f.(float64) >= 1 {
+			t.Fatalf("random() result %v, expected in [0, 1)", f)
+		}
+	}
+	if len(seen) != 5 {
+		t.Errorf("randomInt(-2, 2) gave only %v, expected both ends included", seen)
+	}
+
+	if value, _ := nativeRandomInt(interpreter, []any{int64(3), int64(3)}); value != int64(3) {
+		t.Errorf("randomInt(3, 3) result %v, expected 3", value)
+	}
+	// the widest range, one more value no longer fits Int63n
+	if _, err := nativeRandomInt(interpreter, []any{int64(0), int64(math.MaxInt64 - 1)}); err != nil {
+		t.Errorf("randomInt(0, %d) error %v", int64(math.MaxInt64-1), err)
+	}
+
+	tests := []struct {
+		lo, hi any
+	}{
+		{int64(2), int64(1)},
+		{int64(0), int64(math.MaxInt64)},
+		{int64(math.MinInt64), int64(math.MaxInt64)},
+		{int64(0), 1.5},
+		{"a", int64(1)},
+	}
+	for _, tt := range tests {
+		if _, err := nativeRandomInt(interpreter, []any{tt.lo, tt.hi}); err == nil {
+			t.Errorf("randomInt(%v, %v) allowed, expected an error", tt.lo, tt.hi)
+		}
+	}
+}
+
+func TestRandomNatives_EmptyList(t *testing.T) {
+	interpreter := NewInterpreter(NewLox(), WithSeed(1))
+	empty := NewLoxList([]any{})
+
+	if _, err := nativeChoice(interpreter, []any{empty}); err == nil {
+		t.Errorf("choice([]) allowed, expected an error")
+	}
+	if _, err := nativeShuffle(interpreter, []any{empty}); err != nil || len(empty.elements) != 0 {
+		t.Errorf("shuffle([]) error %v, list %v, expected an empty list", err, empty.elements)
+	}
+
+	one := NewLoxList([]any{"only"})
+	if value, _ := nativeChoice(interpreter, []any{one}); value != "only" {
+		t.Errorf("choice([\"only\"]) result %v, expected only", value)
+	}
+	if _, err := nativeChoice(interpreter, []any{"abc"}); err == nil {
+		t.Errorf("choice(\"abc\") allowed, expected a list")
+	}
+	if _, err := nativeShuffle(interpreter, []any{nil}); err == nil {
+		t.Errorf("shuffle(nil) allowed, expected a list")
+	}
+}
@@ -0,0 +1,117 @@
+package golox
+
+import (
+	"fmt"
+	"regexp"
+	"regexp/syntax"
+)
+
+// maxCachedPatterns - bound on compiled patterns kept per interpreter,
+// scripts building patterns on the fly shouldn't grow the cache forever
+const maxCachedPatterns = 256
+
+func defineRegexNatives(globals *Environment) {
+	globals.define("regexMatch", NewNativeFunction("regexMatch", 2, nativeRegexMatch))
+	globals.define("regexFind", NewNativeFunction("regexFind", 2, nativeRegexFind))
+	globals.define("regexFindAll", NewNativeFunction("regexFindAll", 2, nativeRegexFindAll))
+	globals.define("regexReplace", NewNativeFunction("regexReplace", 3, nativeRegexReplace))
+	globals.define("regexSplit", NewNativeFunction("regexSplit", 2, nativeRegexSplit))
+}
+
+// compilePattern - compiled pattern from the cache, compiling it on a miss
+func (i *Interpreter) compilePattern(argument any) (*regexp.Regexp, error) {
+	pattern, ok := argument.(string)
+	if !ok {
+		return nil, fmt.Errorf("Regular expression must be a string.")
+	}
+	if re, ok := i.patterns[pattern]; ok {
+		return re, nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		message := err.Error()
+		if syntaxErr, ok := err.(*syntax.Error); ok {
+			message = syntaxErr.Code.String()
+		}
+		return nil, fmt.Errorf("Invalid regular expression '%s': %s.", pattern, message)
+	}
+	if len(i.patterns) >= maxCachedPatterns {
+		i.patterns = make(map[string]*regexp.Regexp)
+	}
+	i.patterns[pattern] = re
+	return re, nil
+}
+
+func regexArguments(i *Interpreter, arguments []any) (*regexp.Regexp, string, error) {
+	re, err := i.compilePattern(arguments[0])
+	if err != nil {
+		return nil, "", err
+	}
+	text, ok := arguments[1].(string)
+	if !ok {
+		return nil, "", fmt.Errorf("Text to match must be a string.")
+	}
+	return re, text, nil
+}
+
+// regexMatch(pattern, text) - whether the pattern matches anywhere in text
+func nativeRegexMatch(i *Interpreter, arguments []any) (any, error) {
+	re, text, err := regexArguments(i, arguments)
+	if err != nil {
+		return nil, err
+	}
+	return re.MatchString(text), nil
+}
+
+// regexFind(pattern, text) - leftmost match, nil without a match
+func nativeRegexFind(i *Interpreter, arguments []any) (any, error) {
+	re, text, err := regexArguments(i, arguments)
+	if err != nil {
+		return nil, err
+	}
+	loc := re.FindStringIndex(text)
+	if loc == nil {
+		return nil, nil
+	}
+	return text[loc[0]:loc[1]], nil
+}
+
+// regexFindAll(pattern, text) - list of all non-overlapping matches
+func nativeRegexFindAll(i *Interpreter, arguments []any) (any, error) {
+	re, text, err := regexArguments(i, arguments)
+	if err != nil {
+		return nil, err
+	}
+	return stringList(re.FindAllString(text, -1)), nil
+}
+
+// regexReplace(pattern, text, replacement) - $1 or ${name} in replacement
+// expand to the submatches
+func nativeRegexReplace(i *Interpreter, arguments []any) (any, error) {
+	re, text, err := regexArguments(i, arguments)
+	if err != nil {
+		return nil, err
+	}
+	replacement, ok := arguments[2].(string)
+	if !ok {
+		return nil, fmt.Errorf("Replacement must be a string.")
+	}
+	return re.ReplaceAllString(text, replacement), nil
+}
+
+// regexSplit(pattern, text) - list of the text between the matches
+func nativeRegexSplit(i *Interpreter, arguments []any) (any, error) {
+	re, text, err := regexArguments(i, arguments)
+	if err != nil {
+		return nil, err
+	}
+	return stringList(re.Split(text, -1)), nil
+}
+
+func stringList(values []string) *LoxList {
+	elements := make([]any, len(values))
+	for n, value := range values {
+		elements[n] = value
+	}
+	return NewLoxList(elements)
+}
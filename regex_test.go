@@ -0,0 +1,91 @@
+package golox
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestRegexNatives(t *testing.T) {
+	interpreter := NewInterpreter(NewLox())
+	tests := []struct {
+		native    func(*Interpreter, []any) (any, error)
+		arguments []any
+		expected  any
+	}{
+		{nativeRegexMatch, []any{`\d+`, "abc 123"}, true},
+		{nativeRegexMatch, []any{`^\d+$`, "abc 123"}, false},
+		{nativeRegexFind, []any{`\d+`, "a1 b22 c333"}, "1"},
+		{nativeRegexFind, []any{`\d+`, "none"}, nil},
+		{nativeRegexReplace, []any{`(\w+)@(\w+)`, "ann@home bob@work", "$2:$1"}, "home:ann work:bob"},
+		{nativeRegexReplace, []any{`(?P<n>\d)`, "a1b2", "<${n}>"}, "a<1>b<2>"},
+		{nativeRegexReplace, []any{`x`, "abc", "y"}, "abc"},
+	}
+	for _, tt := range tests {
+		value, err := tt.native(interpreter, tt.arguments)
+		if err != nil {
+			t.Errorf("%v error %v", tt.arguments, err)
+			continue
+		}
+		if value != tt.expected {
+			t.Errorf("%v result %v, expected %v", tt.arguments, value, tt.expected)
+		}
+	}
+
+	all, _ := nativeRegexFindAll(interpreter, []any{`\d+`, "a1 b22 c333"})
+	if elements := all.(*LoxList).elements; !reflect.DeepEqual(elements, []any{"1", "22", "333"}) {
+		t.Errorf("regexFindAll result %v, expected [1 22 333]", elements)
+	}
+	parts, _ := nativeRegexSplit(interpreter, []any{`\s*,\s*`, "a , b,c"})
+	if elements := parts.(*LoxList).elements; !reflect.DeepEqual(elements, []any{"a", "b", "c"}) {
+		t.Errorf("regexSplit result %v, expected [a b c]", elements)
+	}
+}
+
+func TestRegexNatives_InvalidPattern(t *testing.T) {
+	var out bytes.Buffer
+	lox := NewLox(WithOutput(&out), WithErrorOutput(&out))
+	err := lox.Run("print 1;\nprint regexMatch(\"(\", \"x\");")
+	if _, ok := err.(RuntimeError); !ok {
+		t.Fatalf("Run error %T %v, expected a RuntimeError", err, err)
+	}
+	expected := "1\nInvalid regular expression '(': missing closing ).\n[line 2]\n"
+	if out.String() != expected {
+		t.Errorf("output %q, expected %q", out.String(), expected)
+	}
+
+	interpreter := NewInterpreter(NewLox())
+	if _, err := nativeRegexMatch(interpreter, []any{1.0, "x"}); err == nil {
+		t.Errorf("regexMatch(1, \"x\") allowed, expected a string pattern")
+	}
+	if _, err := nativeRegexReplace(interpreter, []any{"a", "abc", nil}); err == nil {
+		t.Errorf("regexReplace with a nil replacement allowed, expected a string")
+	}
+}
+
+func TestRegexNatives_PatternCache(t *testing.T) {
+	interpreter := NewInterpreter(NewLox())
+	for n := 0; n < 3*maxCachedPatterns; n++ {
+		if _, err := nativeRegexMatch(interpreter, []any{fmt.Sprintf("^%d$", n), ""}); err != nil {
+			t.Fatal(err)
+		}
+		if len(interpreter.patterns) > maxCachedPatterns {
+			t.Fatalf("%d patterns cached, expected at most %d", len(interpreter.patterns), maxCachedPatterns)
+		}
+	}
+
+	// the patterns evicted and the ones still cached both match correctly
+	for n := 0; n < 3*maxCachedPatterns; n++ {
+		pattern := fmt.Sprintf("^%d$", n)
+		for _, text := range []string{fmt.Sprint(n), fmt.Sprint(n + 1)} {
+			matched, err := nativeRegexMatch(interpreter, []any{pattern, text})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if expected := text == fmt.Sprint(n); matched != expected {
+				t.Errorf("regexMatch(%q, %q) result %v, expected %v", pattern, text, matched, expected)
+			}
+		}
+	}
+}
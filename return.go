@@ -4,13 +4,14 @@ package golox
 // when the return is executed, the interpreter nees to jump all the wat out
 // of the current context and complete the function call
 type ReturnValue struct {
-	value any
+	keyword *Token
+	value   any
 }
 
-func NewReturnValue(value any) ReturnValue {
-	return ReturnValue{value}
+func NewReturnValue(keyword *Token, value any) ReturnValue {
+	return ReturnValue{keyword, value}
 }
 
 func (r ReturnValue) Error() string {
-	return r.value.(string)
+	return "Can't return from top-level code."
 }
@@ -1,7 +1,9 @@
 package golox
 
 import (
+	"fmt"
 	"strconv"
+	"strings"
 )
 
 type Scanner struct {
@@ -11,6 +13,11 @@ type Scanner struct {
 	start   int
 	current int
 	line    int
+	// lineStart - offset of the current line; startLine and column are
+	// where the token being scanned starts, strings may span lines
+	lineStart int
+	startLine int
+	column    int
 }
 
 var keywords = map[string]TokenType{
@@ -46,10 +53,12 @@ func NewScanner(lox *Lox, source string) *Scanner {
 func (s *Scanner) scanTokens() []Token {
 	for !s.isAtEnd() {
 		s.start = s.current
+		s.startLine = s.line
+		s.column = s.start - s.lineStart + 1
 		s.scanToken()
 	}
 
-	s.tokens = append(s.tokens, *NewToken(TkEof, "", nil, s.line))
+	s.tokens = append(s.tokens, Token{TkEof, "", nil, s.line, s.current - s.lineStart + 1, s.current})
 	return s.tokens
 
 }
@@ -71,14 +80,18 @@ func (s *Scanner) scanToken() {
 		s.addToken(TkComma)
 	case '.':
 		s.addToken(TkDot)
-	case '-':
-		s.addToken(TkMinus)
-	case '+':
-		s.addToken(TkPlus)
 	case ';':
 		s.addToken(TkSemicolon)
-	case '*':
-		s.addToken(TkStar)
+	case ':':
+		s.addToken(TkColon)
+	case '%':
+		s.addToken(TkPercent)
+	case '&':
+		s.addToken(TkAmpersand)
+	case '|':
+		s.addToken(TkPipe)
+	case '^':
+		s.addToken(TkCaret)
 	// one or two characters
 	case '!':
 		if s.match('=') {
@@ -92,15 +105,56 @@ func (s *Scanner) scanToken() {
 		} else {
 			s.addToken(TkEqual)
 		}
+	case '-':
+		if s.match('-') {
+			s.addToken(TkMinusMinus)
+		} else if s.match('=') {
+			s.addToken(TkMinusEqual)
+		} else {
+			s.addToken(TkMinus)
+		}
+	case '+':
+		if s.match('+') {
+			s.addToken(TkPlusPlus)
+		} else if s.match('=') {
+			s.addToken(TkPlusEqual)
+		} else {
+			s.addToken(TkPlus)
+		}
+	case '*':
+		if s.match('*') {
+			s.addToken(TkStarStar)
+		} else if s.match('=') {
+			s.addToken(TkStarEqual)
+		} else {
+			s.addToken(TkStar)
+		}
+	case '~':
+		// '~/' is integer division as '//' already starts a comment
+		if s.match('/') {
+			s.addToken(TkTildeSlash)
+		} else {
+			s.addToken(TkTilde)
+		}
+	case '?':
+		if s.match('?') {
+			s.addToken(TkQuestionQuestion)
+		} else {
+			s.addToken(TkQuestion)
+		}
 	case '<':
 		if s.match('=') {
 			s.addToken(TkLessEqual)
+		} else if s.match('<') {
+			s.addToken(TkLessLess)
 		} else {
 			s.addToken(TkLess)
 		}
 	case '>':
 		if s.match('=') {
 			s.addToken(TkGreaterEqual)
+		} else if s.match('>') {
+			s.addToken(TkGreaterGreater)
 		} else {
 			s.addToken(TkGreater)
 		}
@@ -112,6 +166,8 @@ func (s *Scanner) scanToken() {
 			for s.peek() != '\n' && !s.isAtEnd() {
 				s.advance()
 			}
+		} else if s.match('=') {
+			s.addToken(TkSlashEqual)
 		} else {
 			s.addToken(TkSlash)
 		}
@@ -123,6 +179,7 @@ func (s *Scanner) scanToken() {
 		break
 	case '\n':
 		s.line++
+		s.lineStart = s.current
 	case '"':
 		// string literal
 		s.string()
@@ -134,7 +191,7 @@ func (s *Scanner) scanToken() {
 		} else if s.isAlpha(c) {
 			s.identifier()
 		} else {
-			s.lox.Error(s.line, "Unexpected character.")
+			s.error("Unexpected character.")
 			break
 		}
 	}
@@ -158,11 +215,12 @@ func (s *Scanner) string() {
 	for s.peek() != '"' && !s.isAtEnd() {
 		if s.peek() == '\n' {
 			s.line++
+			s.lineStart = s.current + 1
 		}
 		s.advance()
 	}
 	if s.isAtEnd() {
-		s.lox.Error(s.line, "Unterminated string.")
+		s.error("Unterminated string.")
 		return
 	}
 
@@ -174,24 +232,85 @@ func (s *Scanner) string() {
 }
 
 // number - consume number literal
+// integers are int64 and floats need a fractional part, so 1 and 1.0 differ;
+// integers may use 0x, 0b or 0o prefixes and '_' between digits
 func (s *Scanner) number() {
-	for s.isDigit(s.peek()) {
-		s.advance()
+	if s.source[s.start] == '0' && s.isRadixPrefix(s.peek()) {
+		s.radixNumber()
+		return
 	}
+
+	s.digits(s.isDigit)
+	isFloat := false
 	if s.peek() == '.' && s.isDigit(s.peekNext()) {
+		isFloat = true
 		s.advance()
-		for s.isDigit(s.peek()) {
+		s.digits(s.isDigit)
+	}
+
+	text := strings.ReplaceAll(s.source[s.start:s.current], "_", "")
+	if isFloat {
+		num, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			s.error("Parse number literal error.")
+			return
+		}
+		s.addTokenWithLiteral(TkNumber, num)
+		return
+	}
+	num, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		s.error("Integer literal out of range.")
+		return
+	}
+	s.addTokenWithLiteral(TkNumber, num)
+}
+
+// radixNumber - consume 0x, 0b or 0o integer literal
+func (s *Scanner) radixNumber() {
+	prefix := s.advance()
+	isRadixDigit := s.isHexDigit
+	switch prefix {
+	case 'b', 'B':
+		isRadixDigit = func(c byte) bool { return c == '0' || c == '1' }
+	case 'o', 'O':
+		isRadixDigit = func(c byte) bool { return c >= '0' && c <= '7' }
+	}
+	if !isRadixDigit(s.peek()) {
+		s.error(fmt.Sprintf("Expect digits after '0%c'.", prefix))
+		return
+	}
+	s.digits(isRadixDigit)
+	if s.isAlphaNumeric(s.peek()) {
+		s.error("Invalid digit in number literal.")
+		for s.isAlphaNumeric(s.peek()) {
 			s.advance()
 		}
+		return
 	}
-	num, err := strconv.ParseFloat(s.source[s.start:s.current], 64)
+
+	// base 0 understands the prefix and the '_' separators
+	num, err := strconv.ParseInt(s.source[s.start:s.current], 0, 64)
 	if err != nil {
-		s.lox.Error(s.line, "Parse number literal error.")
+		s.error("Integer literal out of range.")
 		return
 	}
 	s.addTokenWithLiteral(TkNumber, num)
 }
 
+// digits - consume digits, allowing a single '_' between two digits
+func (s *Scanner) digits(isDigit func(c byte) bool) {
+	for {
+		if isDigit(s.peek()) {
+			s.advance()
+		} else if s.peek() == '_' && isDigit(s.peekNext()) {
+			s.advance()
+		} else {
+			return
+		}
+	}
+}
+
 // match - is like conditional advance()
 func (s *Scanner) match(expected byte) bool {
 	if s.isAtEnd() {
@@ -240,6 +359,30 @@ func (s *Scanner) isDigit(c byte) bool {
 	return c >= '0' && c <= '9'
 }
 
+func (s *Scanner) isHexDigit(c byte) bool {
+	return s.isDigit(c) ||
+		(c >= 'a' && c <= 'f') ||
+		(c >= 'A' && c <= 'F')
+}
+
+func (s *Scanner) isRadixPrefix(c byte) bool {
+	switch c {
+	case 'x', 'X', 'b', 'B', 'o', 'O':
+		return true
+	}
+	return false
+}
+
+// error - report at the token being scanned, with its column unless a
+// string ran on to later lines
+func (s *Scanner) error(message string) {
+	column := 0
+	if s.line == s.startLine {
+		column = s.column
+	}
+	s.lox.report(CodeSyntaxError, s.line, column, "", message)
+}
+
 func (s *Scanner) isAtEnd() bool {
 	return s.current >= len(s.source)
 }
@@ -250,5 +393,5 @@ func (s *Scanner) addToken(kind TokenType) {
 
 func (s *Scanner) addTokenWithLiteral(kind TokenType, literal interface{}) {
 	text := s.source[s.start:s.current]
-	s.tokens = append(s.tokens, Token{kind, text, literal, s.line})
+	s.tokens = append(s.tokens, Token{kind, text, literal, s.startLine, s.column, s.start})
 }
@@ -47,13 +47,17 @@ func (expr *Expression) Accept(visitor StmtVisitor) (any, error) {
 type Function struct {
   name *Token
   params []*Token
+  paramTypes []*Token
+  returnType *Token
   body []Stmt
 }
 
-func NewFunction(name *Token, params []*Token, body []Stmt) *Function {
+func NewFunction(name *Token, params []*Token, paramTypes []*Token, returnType *Token, body []Stmt) *Function {
   return &Function{
     name: name,
     params: params,
+    paramTypes: paramTypes,
+    returnType: returnType,
     body: body,
   }
 }
@@ -112,12 +116,14 @@ func (expr *Return) Accept(visitor StmtVisitor) (any, error) {
 
 type Var struct {
   name *Token
+  typeName *Token
   initializer Expr
 }
 
-func NewVar(name *Token, initializer Expr) *Var {
+func NewVar(name *Token, typeName *Token, initializer Expr) *Var {
   return &Var{
     name: name,
+    typeName: typeName,
     initializer: initializer,
   }
 }
@@ -0,0 +1,226 @@
+package golox
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// TimeSource - where the time natives read the time and how they wait,
+// replace it with WithTimeSource to make scripts deterministic in tests
+type TimeSource interface {
+	Now() time.Time
+	Sleep(d time.Duration)
+}
+
+type systemTime struct{}
+
+func (systemTime) Now() time.Time        { return time.Now() }
+func (systemTime) Sleep(d time.Duration) { time.Sleep(d) }
+
+func WithTimeSource(source TimeSource) Option {
+	return func(i *Interpreter) {
+		i.timeSource = source
+		i.started = source.Now()
+	}
+}
+
+// LoxTime - point in time returned by now, parseTime and fromUnix
+type LoxTime struct {
+	time time.Time
+}
+
+func (t LoxTime) String() string {
+	return t.time.Format(time.RFC3339Nano)
+}
+
+// layouts - names accepted by formatTime and parseTime besides Go layouts
+var layouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"DateTime":    "2006-01-02 15:04:05",
+	"DateOnly":    "2006-01-02",
+	"TimeOnly":    "15:04:05",
+	"Kitchen":     time.Kitchen,
+}
+
+func defineTimeNatives(globals *Environment) {
+	globals.define("clock", NewClock())
+	globals.define("monotonic", NewNativeFunction("monotonic", 0, nativeMonotonic))
+	globals.define("now", NewNativeFunction("now", 0, nativeNow))
+	globals.define("fromUnix", NewNativeFunction("fromUnix", 1, nativeFromUnix))
+	globals.define("unix", NewNativeFunction("unix", 1, nativeUnix))
+	globals.define("formatTime", NewNativeFunction("formatTime", 2, nativeFormatTime))
+	globals.define("parseTime", NewNativeFunction("parseTime", 2, nativeParseTime))
+	globals.define("timeAdd", NewNativeFunction("timeAdd", 2, nativeTimeAdd))
+	globals.define("timeDiff", NewNativeFunction("timeDiff", 2, nativeTimeDiff))
+	globals.define("duration", NewNativeFunction("duration", 1, nativeDuration))
+	globals.define("formatDuration", NewNativeFunction("formatDuration", 1, nativeFormatDuration))
+	globals.define("sleep", NewNativeFunction("sleep", 1, nativeSleep))
+}
+
+// monotonic() - seconds since the interpreter started, unaffected by
+// changes to the wall clock
+func nativeMonotonic(i *Interpreter, arguments []any) (any, error) {
+	return i.timeSource.Now().Sub(i.started).Seconds(), nil
+}
+
+func nativeNow(i *Interpreter, arguments []any) (any, error) {
+	return LoxTime{i.timeSource.Now()}, nil
+}
+
+// fromUnix(seconds) - time from seconds since the Unix epoch
+func nativeFromUnix(i *Interpreter, arguments []any) (any, error) {
+	seconds, err := durationArgument(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+	return LoxTime{time.Unix(0, 0).Add(seconds).UTC()}, nil
+}
+
+// unix(time) - seconds since the Unix epoch with fractional part
+func nativeUnix(i *Interpreter, arguments []any) (any, error) {
+	t, err := timeArgument(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+	return float64(t.UnixNano()) / float64(time.Second), nil
+}
+
+// formatTime(time, layout) - layout is a name from layouts or a Go layout
+func nativeFormatTime(i *Interpreter, arguments []any) (any, error) {
+	t, err := timeArgument(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+	layout, err := layoutArgument(arguments[1])
+	if err != nil {
+		return nil, err
+	}
+	return t.Format(layout), nil
+}
+
+func nativeParseTime(i *Interpreter, arguments []any) (any, error) {
+	text, ok := arguments[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("Time to parse must be a string.")
+	}
+	layout, err := layoutArgument(arguments[1])
+	if err != nil {
+		return nil, err
+	}
+	t, err := time.Parse(layout, text)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot parse time '%s' with layout '%s'.", text, arguments[1])
+	}
+	return LoxTime{t}, nil
+}
+
+// timeAdd(time, seconds) - shift a time by a duration in seconds
+func nativeTimeAdd(i *Interpreter, arguments []any) (any, error) {
+	t, err := timeArgument(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+	d, err := durationArgument(arguments[1])
+	if err != nil {
+		return nil, err
+	}
+	return LoxTime{t.Add(d)}, nil
+}
+
+// timeDiff(a, b) - seconds from b to a
+func nativeTimeDiff(i *Interpreter, arguments []any) (any, error) {
+	a, err := timeArgument(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+	b, err := timeArgument(arguments[1])
+	if err != nil {
+		return nil, err
+	}
+	return a.Sub(b).Seconds(), nil
+}
+
+// duration(text) - seconds in a duration such as "1h30m" or "250ms"
+func nativeDuration(i *Interpreter, arguments []any) (any, error) {
+	text, ok := arguments[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("Duration to parse must be a string.")
+	}
+	d, err := time.ParseDuration(text)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid duration '%s'.", text)
+	}
+	return d.Seconds(), nil
+}
+
+func nativeFormatDuration(i *Interpreter, arguments []any) (any, error) {
+	d, err := durationArgument(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+	return d.String(), nil
+}
+
+// sleep(seconds) - pause the script
+func nativeSleep(i *Interpreter, arguments []any) (any, error) {
+	d, err := durationArgument(arguments[0])
+	if err != nil {
+		return nil, err
+	}
+	if d < 0 {
+		return nil, fmt.Errorf("Sleep duration must not be negative.")
+	}
+	if _, ok := i.timeSource.(systemTime); ok {
+		// wake up early when the host cancels the script
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+		select {
+		case <-timer.C:
+		case <-i.ctx.Done():
+			return nil, NewCancelError(i.ctx.Err())
+		}
+		return nil, nil
+	}
+	i.timeSource.Sleep(d)
+	return nil, nil
+}
+
+func timeArgument(argument any) (time.Time, error) {
+	t, ok := argument.(LoxTime)
+	if !ok {
+		return time.Time{}, fmt.Errorf("Expect a time value.")
+	}
+	return t.time, nil
+}
+
+// durationArgument - seconds as a Lox number to time.Duration, which
+// holds about 292 years either way
+func durationArgument(argument any) (time.Duration, error) {
+	if !isNumber(argument) {
+		return 0, fmt.Errorf("Duration must be a number of seconds.")
+	}
+	nanoseconds := toFloat(argument) * float64(time.Second)
+	// float64(math.MaxInt64) rounds up to 2^63, which no longer fits
+	if math.IsNaN(nanoseconds) || nanoseconds >= math.MaxInt64 || nanoseconds < math.MinInt64 {
+		seconds := fmt.Sprint(argument)
+		if f, ok := argument.(float64); ok {
+			seconds = formatFloat(f)
+		}
+		return 0, fmt.Errorf("Duration of %s seconds is out of range.", seconds)
+	}
+	return time.Duration(nanoseconds), nil
+}
+
+func layoutArgument(argument any) (string, error) {
+	layout, ok := argument.(string)
+	if !ok {
+		return "", fmt.Errorf("Time layout must be a string.")
+	}
+	if named, ok := layouts[layout]; ok {
+		return named, nil
+	}
+	return layout, nil
+}
@@ -0,0 +1,160 @@
+package golox
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeTime - only moves when the script sleeps
+type fakeTime struct {
+	now time.Time
+}
+
+func (f *fakeTime) Now() time.Time        { return f.now }
+func (f *fakeTime) Sleep(d time.Duration) { f.now = f.now.Add(d) }
+
+func TestTimeNatives_FakeTimeSource(t *testing.T) {
+	source := &fakeTime{now: time.Date(2024, 2, 29, 13, 45, 0, 0, time.UTC)}
+	interpreter := NewInterpreter(NewLox(), WithTimeSource(source))
+
+	if _, err := nativeSleep(interpreter, []any{1.5}); err != nil {
+		t.Fatal(err)
+	}
+
+	elapsed, _ := nativeMonotonic(interpreter, nil)
+	if elapsed != 1.5 {
+		t.Errorf("monotonic() result %v, expected 1.5", elapsed)
+	}
+	seconds, _ := NewClock().call(interpreter, nil)
+	if seconds != 1709214301.5 {
+		t.Errorf("clock() result %v, expected 1709214301.5", seconds)
+	}
+	now, _ := nativeNow(interpreter, nil)
+	formatted, _ := nativeFormatTime(interpreter, []any{now, "DateTime"})
+	if formatted != "2024-02-29 13:45:01" {
+		t.Errorf("formatTime(now(), \"DateTime\") result %v, expected 2024-02-29 13:45:01", formatted)
+	}
+}
+
+func TestTimeNatives_ParseTime(t *testing.T) {
+	tests := []struct {
+		text     string
+		layout   string
+		expected string
+	}{
+		{"2024-02-29T13:45:00Z", "RFC3339", "2024-02-29T13:45:00Z"},
+		{"2024-02-29T13:45:00.25+01:00", "RFC3339Nano", "2024-02-29T13:45:00.25+01:00"},
+		{"Thu, 29 Feb 2024 13:45:00 UTC", "RFC1123", "2024-02-29T13:45:00Z"},
+		{"2024-02-29 13:45:00", "DateTime", "2024-02-29T13:45:00Z"},
+		{"2024-02-29", "DateOnly", "2024-02-29T00:00:00Z"},
+		{"13:45:00", "TimeOnly", "0000-01-01T13:45:00Z"},
+		{"1:45PM", "Kitchen", "0000-01-01T13:45:00Z"},
+		{"29/02/2024", "02/01/2006", "2024-02-29T00:00:00Z"},
+	}
+	for _, tt := range tests {
+		parsed, err := nativeParseTime(nil, []any{tt.text, tt.layout})
+		if err != nil {
+			t.Errorf("parseTime(%q, %q) error %v", tt.text, tt.layout, err)
+			continue
+		}
+		if result := parsed.(LoxTime).String(); result != tt.expected {
+			t.Errorf("parseTime(%q, %q) result %s, expected %s", tt.text, tt.layout, result, tt.expected)
+		}
+	}
+}
+
+func TestTimeNatives_Durations(t *testing.T) {
+	for _, tt := range []struct {
+		text    string
+		seconds float64
+	}{
+		{"1h30m", 5400},
+		{"250ms", 0.25},
+		{"-2s", -2},
+	} {
+		seconds, err := nativeDuration(nil, []any{tt.text})
+		if err != nil || seconds != tt.seconds {
+			t.Errorf("duration(%q) result %v (%v), expected %v", tt.text, seconds, err, tt.seconds)
+		}
+	}
+
+	for _, tt := range []struct {
+		seconds  any
+		expected string
+	}{
+		{int64(5400), "1h30m0s"},
+		{0.25, "250ms"},
+		{-2.5, "-2.5s"},
+		{int64(0), "0s"},
+	} {
+		formatted, err := nativeFormatDuration(nil, []any{tt.seconds})
+		if err != nil || formatted != tt.expected {
+			t.Errorf("formatDuration(%v) result %v (%v), expected %s", tt.seconds, formatted, err, tt.expected)
+		}
+	}
+}
+
+func TestTimeNatives_AddAndDiff(t *testing.T) {
+	start := LoxTime{time.Date(2024, 2, 28, 23, 0, 0, 0, time.UTC)}
+	later, err := nativeTimeAdd(nil, []any{start, int64(7200)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := later.(LoxTime).String(); result != "2024-02-29T01:00:00Z" {
+		t.Errorf("timeAdd(start, 7200) result %s, expected 2024-02-29T01:00:00Z", result)
+	}
+	earlier, _ := nativeTimeAdd(nil, []any{start, -0.5})
+	if result := earlier.(LoxTime).String(); result != "2024-02-28T22:59:59.5Z" {
+		t.Errorf("timeAdd(start, -0.5) result %s, expected 2024-02-28T22:59:59.5Z", result)
+	}
+
+	if diff, _ := nativeTimeDiff(nil, []any{later, start}); diff != 7200.0 {
+		t.Errorf("timeDiff(later, start) result %v, expected 7200", diff)
+	}
+	if diff, _ := nativeTimeDiff(nil, []any{start, earlier}); diff != 0.5 {
+		t.Errorf("timeDiff(start, earlier) result %v, expected 0.5", diff)
+	}
+}
+
+func TestTimeNatives_ArgumentErrors(t *testing.T) {
+	start := LoxTime{time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)}
+	tests := []struct {
+		name     string
+		native   func(*Interpreter, []any) (any, error)
+		args     []any
+		expected string
+	}{
+		{"parseTime", nativeParseTime, []any{1.0, "RFC3339"}, "Time to parse must be a string."},
+		{"parseTime", nativeParseTime, []any{"2024-02-29", nil}, "Time layout must be a string."},
+		{"parseTime", nativeParseTime, []any{"yesterday", "DateOnly"}, "Cannot parse time 'yesterday' with layout 'DateOnly'."},
+		{"duration", nativeDuration, []any{int64(1)}, "Duration to parse must be a string."},
+		{"duration", nativeDuration, []any{"soon"}, "Invalid duration 'soon'."},
+		{"formatDuration", nativeFormatDuration, []any{"1s"}, "Duration must be a number of seconds."},
+		{"formatDuration", nativeFormatDuration, []any{1e10}, "Duration of 10000000000 seconds is out of range."},
+		{"formatDuration", nativeFormatDuration, []any{math.NaN()}, "Duration of NaN seconds is out of range."},
+		{"timeAdd", nativeTimeAdd, []any{"now", int64(1)}, "Expect a time value."},
+		{"timeAdd", nativeTimeAdd, []any{start, nil}, "Duration must be a number of seconds."},
+		{"timeAdd", nativeTimeAdd, []any{start, int64(math.MaxInt64)}, "Duration of 9223372036854775807 seconds is out of range."},
+		{"timeAdd", nativeTimeAdd, []any{start, -1e10}, "Duration of -10000000000 seconds is out of range."},
+		{"timeDiff", nativeTimeDiff, []any{start, 1.0}, "Expect a time value."},
+		{"fromUnix", nativeFromUnix, []any{math.Inf(1)}, "Duration of Infinity seconds is out of range."},
+	}
+	for _, tt := range tests {
+		_, err := tt.native(nil, tt.args)
+		if err == nil || err.Error() != tt.expected {
+			t.Errorf("%s(%v) error %v, expected %q", tt.name, tt.args, err, tt.expected)
+		}
+	}
+
+	// the error reaches the script as a runtime error on the call
+	var out strings.Builder
+	lox := NewLox(WithOutput(&out), WithErrorOutput(&out))
+	if _, ok := lox.Run("print 1;\nsleep(1000000000000);").(RuntimeError); !ok {
+		t.Errorf("sleep(1000000000000) did not fail with a RuntimeError")
+	}
+	if expected := "1\nDuration of 1000000000000 seconds is out of range.\n[line 2]\n"; out.String() != expected {
+		t.Errorf("output %q, expected %q", out.String(), expected)
+	}
+}
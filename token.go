@@ -7,12 +7,37 @@ type Token struct {
 	lexeme  string
 	literal interface{}
 	line    int
+	// column - byte position of the lexeme in its line from 1, 0 when
+	// the token did not come from the scanner
+	column int
+	// offset - byte position of the lexeme in the source
+	offset int
 }
 
 func NewToken(kind TokenType, lexeme string, literal interface{}, line int) *Token {
-	return &Token{kind, lexeme, literal, line}
+	return &Token{kind: kind, lexeme: lexeme, literal: literal, line: line}
 }
 
 func (t Token) String() string {
-	return fmt.Sprintf("token: %d %s %v", t.kind, t.lexeme, t.literal)
+	return fmt.Sprintf("token: %s %s %v", t.kind, t.lexeme, t.literal)
+}
+
+func (t Token) Kind() TokenType {
+	return t.kind
+}
+
+func (t Token) Literal() any {
+	return t.literal
+}
+
+func (t Token) Lexeme() string {
+	return t.lexeme
+}
+
+func (t Token) Line() int {
+	return t.line
+}
+
+func (t Token) Column() int {
+	return t.column
 }
@@ -0,0 +1,52 @@
+package golox
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// ScanTokens - the tokens of source up to and including Eof; scan errors
+// are reported as usual and ErrSyntax is returned with the tokens found
+func ScanTokens(source string, options ...Option) ([]Token, error) {
+	lox := NewLox(options...)
+	tokens := NewScanner(lox, source).scanTokens()
+	if lox.hadError {
+		return tokens, ErrSyntax
+	}
+	return tokens, nil
+}
+
+// lexemeEscaper - keeps a token on one line, strings may span several
+var lexemeEscaper = strings.NewReplacer("\n", `\n`, "\r", `\r`, "\t", `\t`)
+
+// WriteTokens - one token per line with its line:column, kind, lexeme and
+// the literal value of numbers and strings
+func WriteTokens(w io.Writer, tokens []Token) error {
+	var table strings.Builder
+	tw := tabwriter.NewWriter(&table, 0, 8, 2, ' ', 0)
+	for _, token := range tokens {
+		literal := ""
+		switch v := token.literal.(type) {
+		case int64:
+			literal = strconv.FormatInt(v, 10)
+		case float64:
+			literal, _ = floatLiteral(v)
+		case string:
+			literal = strconv.Quote(v)
+		}
+		fmt.Fprintf(tw, "%d:%d\t%s\t%s\t%s\n", token.line, token.column, token.kind,
+			lexemeEscaper.Replace(token.lexeme), literal)
+	}
+	tw.Flush()
+
+	// the literal column is mostly empty
+	lines := strings.SplitAfter(table.String(), "\n")
+	for n, line := range lines {
+		lines[n] = strings.TrimRight(line, " \n")
+	}
+	_, err := io.WriteString(w, strings.Join(lines, "\n"))
+	return err
+}
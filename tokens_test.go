@@ -0,0 +1,60 @@
+package golox
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestTokenTypeString(t *testing.T) {
+	tests := map[TokenType]string{
+		TkLeftParen:        "LeftParen",
+		TkQuestionQuestion: "QuestionQuestion",
+		TkNumber:           "Number",
+		TkWhile:            "While",
+		TkEof:              "Eof",
+		TokenType(-1):      "TokenType(-1)",
+	}
+	for kind, expected := range tests {
+		if result := kind.String(); result != expected {
+			t.Errorf("TokenType.String result %s, expected %s", result, expected)
+		}
+	}
+}
+
+func TestWriteTokens(t *testing.T) {
+	source := "var x = 1.0;\n  print \"a\nb\" + 0x10; // done\n"
+	tokens, err := ScanTokens(source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := WriteTokens(&out, tokens); err != nil {
+		t.Fatal(err)
+	}
+	expected := "" +
+		"1:1   Var         var\n" +
+		"1:5   Identifier  x\n" +
+		"1:7   Equal       =\n" +
+		"1:9   Number      1.0     1.0\n" +
+		"1:12  Semicolon   ;\n" +
+		"2:3   Print       print\n" +
+		"2:9   String      \"a\\nb\"  \"a\\nb\"\n" +
+		"3:4   Plus        +\n" +
+		"3:6   Number      0x10    16\n" +
+		"3:10  Semicolon   ;\n" +
+		"4:1   Eof\n"
+	if out.String() != expected {
+		t.Errorf("WriteTokens result\n%s\nexpected\n%s", out.String(), expected)
+	}
+}
+
+func TestScanTokensError(t *testing.T) {
+	tokens, err := ScanTokens("var @ x;", WithErrorOutput(io.Discard))
+	if err != ErrSyntax {
+		t.Errorf("ScanTokens error %v, expected ErrSyntax", err)
+	}
+	if len(tokens) != 4 || tokens[1].Column() != 7 {
+		t.Errorf("ScanTokens tokens %v", tokens)
+	}
+}
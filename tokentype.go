@@ -1,5 +1,7 @@
 package golox
 
+import "fmt"
+
 type TokenType int
 
 // Prefix with Tk...
@@ -14,8 +16,13 @@ const (
 	TkMinus
 	TkPlus
 	TkSemicolon
+	TkColon
 	TkSlash
 	TkStar
+	TkPercent
+	TkAmpersand
+	TkPipe
+	TkCaret
 
 	// One or two character token
 	TkBang
@@ -26,6 +33,19 @@ const (
 	TkGreaterEqual
 	TkLess
 	TkLessEqual
+	TkStarStar
+	TkTilde
+	TkTildeSlash
+	TkLessLess
+	TkGreaterGreater
+	TkPlusEqual
+	TkMinusEqual
+	TkStarEqual
+	TkSlashEqual
+	TkPlusPlus
+	TkMinusMinus
+	TkQuestion
+	TkQuestionQuestion
 
 	// Literal
 	TkIdentifier
@@ -53,11 +73,70 @@ const (
 	TkEof
 )
 
-// TODO - implement string function for readable token type
-// func (t TokenType) String() string {
-// 	switch t {
-// 	case TkLeftParen:
-// 		return "LeftParen"
-// 	}
-// 	return "not recognised"
-// }
+// tokenTypeNames - readable names, the constants without their Tk prefix
+var tokenTypeNames = [...]string{
+	TkLeftParen:        "LeftParen",
+	TkRightParen:       "RightParen",
+	TkLeftBrace:        "LeftBrace",
+	TkRightBrace:       "RightBrace",
+	TkComma:            "Comma",
+	TkDot:              "Dot",
+	TkMinus:            "Minus",
+	TkPlus:             "Plus",
+	TkSemicolon:        "Semicolon",
+	TkColon:            "Colon",
+	TkSlash:            "Slash",
+	TkStar:             "Star",
+	TkPercent:          "Percent",
+	TkAmpersand:        "Ampersand",
+	TkPipe:             "Pipe",
+	TkCaret:            "Caret",
+	TkBang:             "Bang",
+	TkBangEqual:        "BangEqual",
+	TkEqual:            "Equal",
+	TkEqualEqual:       "EqualEqual",
+	TkGreater:          "Greater",
+	TkGreaterEqual:     "GreaterEqual",
+	TkLess:             "Less",
+	TkLessEqual:        "LessEqual",
+	TkStarStar:         "StarStar",
+	TkTilde:            "Tilde",
+	TkTildeSlash:       "TildeSlash",
+	TkLessLess:         "LessLess",
+	TkGreaterGreater:   "GreaterGreater",
+	TkPlusEqual:        "PlusEqual",
+	TkMinusEqual:       "MinusEqual",
+	TkStarEqual:        "StarEqual",
+	TkSlashEqual:       "SlashEqual",
+	TkPlusPlus:         "PlusPlus",
+	TkMinusMinus:       "MinusMinus",
+	TkQuestion:         "Question",
+	TkQuestionQuestion: "QuestionQuestion",
+	TkIdentifier:       "Identifier",
+	TkString:           "String",
+	TkNumber:           "Number",
+	TkAnd:              "And",
+	TkClass:            "Class",
+	TkElse:             "Else",
+	TkFalse:            "False",
+	TkFun:              "Fun",
+	TkFor:              "For",
+	TkIf:               "If",
+	TkNil:              "Nil",
+	TkOr:               "Or",
+	TkPrint:            "Print",
+	TkReturn:           "Return",
+	TkSuper:            "Super",
+	TkThis:             "This",
+	TkTrue:             "True",
+	TkVar:              "Var",
+	TkWhile:            "While",
+	TkEof:              "Eof",
+}
+
+func (t TokenType) String() string {
+	if t >= 0 && int(t) < len(tokenTypeNames) {
+		return tokenTypeNames[t]
+	}
+	return fmt.Sprintf("TokenType(%d)", int(t))
+}
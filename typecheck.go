@@ -0,0 +1,364 @@
+package golox
+
+import "fmt"
+
+// loxType - static type used by the checker, values without an annotation
+// or a type the checker can infer are any and never cause an error
+type loxType string
+
+const (
+	typeAny      loxType = "any"
+	typeNumber   loxType = "number"
+	typeString   loxType = "string"
+	typeBool     loxType = "bool"
+	typeNil      loxType = "nil"
+	typeList     loxType = "list"
+	typeMap      loxType = "map"
+	typeFunction loxType = "function"
+)
+
+var typeNames = map[string]loxType{
+	"any":      typeAny,
+	"number":   typeNumber,
+	"string":   typeString,
+	"bool":     typeBool,
+	"nil":      typeNil,
+	"list":     typeList,
+	"map":      typeMap,
+	"function": typeFunction,
+}
+
+// typedName - what the checker knows about a variable, function is set
+// for function declarations so calls can be checked against it
+type typedName struct {
+	typ      loxType
+	function *Function
+}
+
+// WithTypeCheck - type check scripts before running them, off by default
+// so untyped programs run as they always did
+func WithTypeCheck() Option {
+	return func(i *Interpreter) {
+		i.typeCheck = true
+	}
+}
+
+// TypeChecker - gradual type checking between parsing and execution;
+// mismatches are reported like syntax errors so the program never runs
+type TypeChecker struct {
+	lox     *Lox
+	globals map[string]typedName
+	scopes  []map[string]typedName
+	// function - the declaration whose body is being checked
+	function *Function
+}
+
+func NewTypeChecker(lox *Lox) *TypeChecker {
+	return &TypeChecker{
+		lox:     lox,
+		globals: make(map[string]typedName),
+	}
+}
+
+func (c *TypeChecker) Check(statements []Stmt) {
+	// globals are late bound, functions may use ones declared below them
+	declared := make(map[string]bool)
+	for _, stmt := range statements {
+		var name string
+		var typed typedName
+		switch s := stmt.(type) {
+		case *Var:
+			name, typed = s.name.lexeme, typedName{typ: c.annotation(s.typeName)}
+		case *Function:
+			name, typed = s.name.lexeme, typedName{typ: typeFunction, function: s}
+		default:
+			continue
+		}
+		if declared[name] && c.globals[name] != typed {
+			typed = typedName{typ: typeAny}
+		}
+		declared[name] = true
+		c.globals[name] = typed
+	}
+
+	for _, stmt := range statements {
+		c.stmt(stmt)
+	}
+}
+
+func (c *TypeChecker) error(token *Token, format string, args ...any) {
+	c.lox.tokenError(*token, CodeTypeError, fmt.Sprintf(format, args...))
+}
+
+// annotation - the type named by token, any without an annotation
+func (c *TypeChecker) annotation(token *Token) loxType {
+	if token == nil {
+		return typeAny
+	}
+	if t, ok := typeNames[token.lexeme]; ok {
+		return t
+	}
+	return typeAny
+}
+
+func (c *TypeChecker) checkAnnotation(token *Token) {
+	if token == nil {
+		return
+	}
+	if _, ok := typeNames[token.lexeme]; !ok {
+		c.error(token, "Unknown type '%s'.", token.lexeme)
+	}
+}
+
+func (c *TypeChecker) declare(name *Token, typed typedName) {
+	if len(c.scopes) == 0 {
+		// already known from the first pass
+		return
+	}
+	c.scopes[len(c.scopes)-1][name.lexeme] = typed
+}
+
+func (c *TypeChecker) lookup(name string) typedName {
+	for n := len(c.scopes) - 1; n >= 0; n-- {
+		if typed, ok := c.scopes[n][name]; ok {
+			return typed
+		}
+	}
+	if typed, ok := c.globals[name]; ok {
+		return typed
+	}
+	return typedName{typ: typeAny}
+}
+
+// assignable - nil fits every type, like an uninitialized variable
+func assignable(to loxType, from loxType) bool {
+	return to == typeAny || from == typeAny || from == typeNil || to == from
+}
+
+func (c *TypeChecker) stmt(stmt Stmt) {
+	switch s := stmt.(type) {
+	case *Block:
+		c.scopes = append(c.scopes, make(map[string]typedName))
+		for _, statement := range s.statements {
+			c.stmt(statement)
+		}
+		c.scopes = c.scopes[:len(c.scopes)-1]
+	case *Expression:
+		c.expr(s.expression)
+	case *Function:
+		c.declare(s.name, typedName{typ: typeFunction, function: s})
+		c.checkAnnotation(s.returnType)
+
+		scope := make(map[string]typedName)
+		for n, param := range s.params {
+			c.checkAnnotation(s.paramTypes[n])
+			scope[param.lexeme] = typedName{typ: c.annotation(s.paramTypes[n])}
+		}
+		enclosing := c.function
+		c.function = s
+		c.scopes = append(c.scopes, scope)
+		for _, statement := range s.body {
+			c.stmt(statement)
+		}
+		c.scopes = c.scopes[:len(c.scopes)-1]
+		c.function = enclosing
+	case *If:
+		c.expr(s.condition)
+		c.stmt(s.thenBranch)
+		if s.elseBranch != nil {
+			c.stmt(s.elseBranch)
+		}
+	case *Print:
+		c.expr(s.expression)
+	case *Return:
+		t := typeNil
+		if s.value != nil {
+			t = c.expr(s.value)
+		}
+		if c.function != nil && c.function.returnType != nil {
+			declared := c.annotation(c.function.returnType)
+			if !assignable(declared, t) {
+				c.error(s.keyword, "Cannot return %s from '%s', it returns %s.",
+					t, c.function.name.lexeme, declared)
+			}
+		}
+	case *Var:
+		c.checkAnnotation(s.typeName)
+		declared := c.annotation(s.typeName)
+		if s.initializer != nil {
+			t := c.expr(s.initializer)
+			if !assignable(declared, t) {
+				c.error(s.name, "Cannot assign %s to '%s' of type %s.", t, s.name.lexeme, declared)
+			}
+		}
+		c.declare(s.name, typedName{typ: declared})
+	case *While:
+		c.expr(s.condition)
+		c.stmt(s.body)
+	}
+}
+
+func (c *TypeChecker) expr(expr Expr) loxType {
+	switch e := expr.(type) {
+	case *Assign:
+		t := c.expr(e.value)
+		c.checkAssign(e.name, t)
+		return t
+	case *Binary:
+		return c.binary(e.operator, e.operator.kind, c.expr(e.left), c.expr(e.right))
+	case *Call:
+		return c.call(e)
+	case *CompoundAssign:
+		target := c.expr(e.target)
+		t := c.binary(e.operator, compoundOperators[e.operator.kind], target, c.expr(e.value))
+		if variable, ok := e.target.(*Variable); ok {
+			c.checkAssign(variable.name, t)
+		}
+		return t
+	case *Conditional:
+		c.expr(e.condition)
+		return join(c.expr(e.thenBranch), c.expr(e.elseBranch))
+	case *Get:
+		c.expr(e.object)
+		return typeAny
+	case *Grouping:
+		return c.expr(e.expression)
+	case *Increment:
+		if !isNumeric(c.expr(e.target)) {
+			c.error(e.operator, "Operand must be a number.")
+		}
+		return typeNumber
+	case *Literal:
+		return literalType(e.value)
+	case *Logical:
+		left, right := c.expr(e.left), c.expr(e.right)
+		if e.operator.kind == TkQuestionQuestion && left == typeNil {
+			return right
+		}
+		return join(left, right)
+	case *Set:
+		c.expr(e.object)
+		return c.expr(e.value)
+	case *Unary:
+		right := c.expr(e.right)
+		switch e.operator.kind {
+		case TkBang:
+			return typeBool
+		case TkMinus:
+			if !isNumeric(right) {
+				c.error(e.operator, "Operand must be a number.")
+			}
+		case TkTilde:
+			if !isNumeric(right) {
+				c.error(e.operator, "Operand must be an integer.")
+			}
+		}
+		return typeNumber
+	case *Variable:
+		return c.lookup(e.name.lexeme).typ
+	}
+	return typeAny
+}
+
+func literalType(value any) loxType {
+	switch value.(type) {
+	case nil:
+		return typeNil
+	case int64, float64:
+		return typeNumber
+	case string:
+		return typeString
+	case bool:
+		return typeBool
+	}
+	return typeAny
+}
+
+func isNumeric(t loxType) bool {
+	return t == typeNumber || t == typeAny
+}
+
+// join - the type of a value that is either a or b
+func join(a loxType, b loxType) loxType {
+	if a == b {
+		return a
+	}
+	return typeAny
+}
+
+func (c *TypeChecker) checkAssign(name *Token, t loxType) {
+	declared := c.lookup(name.lexeme).typ
+	if !assignable(declared, t) {
+		c.error(name, "Cannot assign %s to '%s' of type %s.", t, name.lexeme, declared)
+	}
+}
+
+// binary - the result type of operator kind, mirroring the checks of
+// Interpreter.binary; operator is where errors are reported
+func (c *TypeChecker) binary(operator *Token, kind TokenType, left loxType, right loxType) loxType {
+	switch kind {
+	case TkPlus:
+		switch {
+		case left == typeNumber && right == typeNumber:
+			return typeNumber
+		case left == typeString && right == typeString:
+			return typeString
+		case isAddable(left) && isAddable(right) && (left == typeAny || right == typeAny):
+			return typeAny
+		}
+		c.error(operator, "Operands must be two numbers or two strings.")
+		return typeAny
+	case TkGreater, TkGreaterEqual, TkLess, TkLessEqual:
+		if !isNumeric(left) || !isNumeric(right) {
+			c.error(operator, "Operands must be numbers.")
+		}
+		return typeBool
+	case TkBangEqual, TkEqualEqual:
+		return typeBool
+	case TkAmpersand, TkPipe, TkCaret, TkLessLess, TkGreaterGreater:
+		if !isNumeric(left) || !isNumeric(right) {
+			c.error(operator, "Operands must be integers.")
+		}
+		return typeNumber
+	}
+
+	// - * / % ~/ **
+	if !isNumeric(left) || !isNumeric(right) {
+		c.error(operator, "Operands must be numbers.")
+	}
+	return typeNumber
+}
+
+func isAddable(t loxType) bool {
+	return t == typeNumber || t == typeString || t == typeAny
+}
+
+// call - arguments are checked against the parameter annotations when the
+// callee is a known function declaration
+func (c *TypeChecker) call(call *Call) loxType {
+	c.expr(call.callee)
+	arguments := make([]loxType, len(call.arguments))
+	for n, argument := range call.arguments {
+		arguments[n] = c.expr(argument)
+	}
+
+	variable, ok := call.callee.(*Variable)
+	if !ok {
+		return typeAny
+	}
+	function := c.lookup(variable.name.lexeme).function
+	if function == nil {
+		return typeAny
+	}
+	// a wrong argument count is a runtime error, the linter warns about it
+	if len(arguments) == len(function.params) {
+		for n, t := range arguments {
+			declared := c.annotation(function.paramTypes[n])
+			if !assignable(declared, t) {
+				c.error(call.paren, "Argument %d of '%s' must be %s, not %s.",
+					n+1, function.name.lexeme, declared, t)
+			}
+		}
+	}
+	return c.annotation(function.returnType)
+}
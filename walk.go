@@ -0,0 +1,80 @@
+package golox
+
+// inspect - depth-first walk over a Stmt or Expr and everything below it,
+// like go/ast.Inspect; children are skipped when f returns false
+func inspect(node any, f func(node any) bool) {
+	switch n := node.(type) {
+	case nil:
+		return
+	case Stmt:
+		if n == nil || !f(n) {
+			return
+		}
+	case Expr:
+		if n == nil || !f(n) {
+			return
+		}
+	}
+
+	switch n := node.(type) {
+	// statements
+	case *Block:
+		inspectStmts(n.statements, f)
+	case *Expression:
+		inspect(n.expression, f)
+	case *Function:
+		inspectStmts(n.body, f)
+	case *If:
+		inspect(n.condition, f)
+		inspect(n.thenBranch, f)
+		inspect(n.elseBranch, f)
+	case *Print:
+		inspect(n.expression, f)
+	case *Return:
+		inspect(n.value, f)
+	case *Var:
+		inspect(n.initializer, f)
+	case *While:
+		inspect(n.condition, f)
+		inspect(n.body, f)
+
+	// expressions
+	case *Assign:
+		inspect(n.value, f)
+	case *Binary:
+		inspect(n.left, f)
+		inspect(n.right, f)
+	case *Call:
+		inspect(n.callee, f)
+		for _, argument := range n.arguments {
+			inspect(argument, f)
+		}
+	case *CompoundAssign:
+		inspect(n.target, f)
+		inspect(n.value, f)
+	case *Conditional:
+		inspect(n.condition, f)
+		inspect(n.thenBranch, f)
+		inspect(n.elseBranch, f)
+	case *Get:
+		inspect(n.object, f)
+	case *Grouping:
+		inspect(n.expression, f)
+	case *Increment:
+		inspect(n.target, f)
+	case *Logical:
+		inspect(n.left, f)
+		inspect(n.right, f)
+	case *Set:
+		inspect(n.object, f)
+		inspect(n.value, f)
+	case *Unary:
+		inspect(n.right, f)
+	}
+}
+
+func inspectStmts(statements []Stmt, f func(node any) bool) {
+	for _, stmt := range statements {
+		inspect(stmt, f)
+	}
+}